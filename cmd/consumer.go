@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"l1/internal/health"
+)
+
+// runConsumer запускает consumer и дожидается его остановки. Остановка по отмене
+// ctx — штатное завершение. Если же consumer остановился сам (например, сообщение
+// не удалось ни сохранить, ни отправить в DLQ), заказы больше не обрабатываются:
+// процесс помечается неживым, а fail завершает приложение с ненулевым кодом,
+// чтобы оркестратор его перезапустил.
+func runConsumer(ctx context.Context, start func(context.Context) error, kafka *health.Component, registry *health.Registry, fail func(error)) {
+	err := start(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	if err == nil {
+		err = errors.New("consumer остановился без ошибки")
+	}
+	err = fmt.Errorf("consumer остановлен: %w", err)
+	log.Printf("%v", err)
+	kafka.SetError(err)
+	registry.SetFatal(err)
+	fail(err)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"l1/internal/health"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunConsumer_FailsOnUnexpectedStop проверяет, что самостоятельная остановка
+// consumer'а делает процесс неживым и завершает приложение
+func TestRunConsumer_FailsOnUnexpectedStop(t *testing.T) {
+	// --- Arrange ---
+	registry := health.NewRegistry()
+	kafka := registry.Component("kafka")
+	stopErr := errors.New("сообщение offset=7 не обработано")
+	var failed error
+
+	// --- Act ---
+	runConsumer(context.Background(), func(context.Context) error { return stopErr }, kafka, registry,
+		func(err error) { failed = err })
+
+	// --- Assert ---
+	require.ErrorIs(t, failed, stopErr)
+	rr := httptest.NewRecorder()
+	registry.LivenessHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, health.StatusDown, registry.Readiness(context.Background()).Components["kafka"].Status)
+}
+
+// TestRunConsumer_Shutdown проверяет, что остановка по отмене контекста штатная
+func TestRunConsumer_Shutdown(t *testing.T) {
+	registry := health.NewRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	failed := false
+
+	runConsumer(ctx, func(ctx context.Context) error {
+		cancel()
+		return nil
+	}, registry.Component("kafka"), registry, func(error) { failed = true })
+
+	assert.False(t, failed)
+	rr := httptest.NewRecorder()
+	registry.LivenessHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
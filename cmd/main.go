@@ -5,19 +5,33 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"l1/internal/config"
 	"l1/internal/consumer"
 	"l1/internal/database"
+	"l1/internal/health"
+	"l1/internal/metrics"
+	"l1/internal/migrate"
+	"l1/internal/rpc"
 	"l1/internal/server"
+	"l1/internal/validation"
 )
 
 func main() {
 	// Загружаем конфигурацию
 	cfg := config.Load()
 
+	// Отдельная команда: только миграции схемы БД, без запуска сервиса
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Ошибка миграции схемы БД: %v", err)
+		}
+		return
+	}
+
 	// Создаем контекст, который будет отменен при получении сигнала прерывания
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -31,31 +45,213 @@ func main() {
 		cancel()
 	}()
 
+	// fail завершает приложение из-за отказа компонента: после остановки
+	// процесс выходит с ненулевым кодом, чтобы оркестратор его перезапустил
+	var failOnce sync.Once
+	failed := make(chan struct{})
+	fail := func(err error) {
+		failOnce.Do(func() {
+			log.Printf("Критическая ошибка, завершение работы: %v", err)
+			close(failed)
+			cancel()
+		})
+	}
+
+	// Реестр состояния компонентов для /healthz и /readyz
+	healthRegistry := health.NewRegistry()
+
+	// Правила валидации заказов: из файла или встроенные
+	rules := validation.Default()
+	if cfg.ValidationRules != "" {
+		var err error
+		if rules, err = validation.Load(cfg.ValidationRules); err != nil {
+			log.Fatalf("Ошибка загрузки правил валидации: %v", err)
+		}
+		log.Printf("Загружено %d правил валидации из %s", len(rules.Rules), cfg.ValidationRules)
+	}
+	validator := validation.All{rules}
+	if cfg.ValidationContacts {
+		validator = append(validator, validation.Contacts{})
+	}
+	if cfg.ValidationStrict {
+		validator = append(validator, validation.NewConsistency(validation.ConsistencyOptions{
+			AmountTolerance: cfg.ValidationAmountTolerance,
+			PriceTolerance:  cfg.ValidationPriceTolerance,
+			PaymentWindow:   cfg.ValidationPaymentWindow,
+		}))
+	}
+
 	// Подключаемся к базе данных
-	dbStore, err := database.NewPostgresStore(cfg.PostgresURL)
+	dbStore, err := database.NewPostgresStore(cfg.PostgresURL, database.StoreOptions{
+		ConflictMode: database.ConflictMode(cfg.OrderConflictMode),
+		LoadStrategy: database.LoadStrategy(cfg.OrderLoadStrategy),
+	})
 	if err != nil {
 		log.Fatalf("Ошибка подключения к БД: %v", err)
 	}
 
+	// Приводим схему БД к версии, которую ожидает сервис
+	if cfg.MigrateOnStart {
+		m, err := migrate.New(dbStore.DB)
+		if err == nil {
+			err = migrateUp(ctx, m)
+		}
+		if err != nil {
+			log.Fatalf("Ошибка миграции схемы БД: %v", err)
+		}
+	}
+
 	// Создаем слой для работы с кэшем (in-memory)
-	memCache := database.NewMemoryCache(1 * time.Hour) // инвалидация кеша через TTL 1 час
+	memCache := database.NewMemoryCacheWithOptions(database.CacheOptions{
+		TTL:        cfg.CacheTTL, // инвалидация кеша через TTL (по умолчанию 1 час)
+		MaxEntries: cfg.CacheMaxEntries,
+		MaxBytes:   cfg.CacheMaxBytes,
+		Policy:     database.EvictionPolicy(cfg.CacheEvictionPolicy),
+	})
 
 	// Создаем основной сервис, передавая ему зависимости (БД и кэш)
 	orderService := database.NewService(dbStore, memCache)
-	defer orderService.Close() //  закрываем соединение с БД и кеш
+	registerCacheMetrics(orderService)
+	healthRegistry.AddCheck("postgres", dbStore.Ping)
 
 	// Запускаем фоновые задачи сервиса (например, прогрев кэша)
-	orderService.RunBackgroundJobs(ctx)
+	orderService.RunBackgroundJobs(ctx, database.WarmUpOptions{
+		Window:      cfg.CacheWarmUpWindow,
+		PageSize:    cfg.CacheWarmUpPageSize,
+		Concurrency: cfg.CacheWarmUpConcurrency,
+		MaxEntries:  cfg.CacheWarmUpMaxEntries,
+		MaxBytes:    cfg.CacheWarmUpMaxBytes,
+		Retries:     cfg.CacheWarmUpRetries,
+		RetryDelay:  cfg.CacheWarmUpRetryDelay,
+		Health:      healthRegistry.Component("cache_warmup"),
+	})
 
 	// Запускаем Kafka consumer в отдельной горутине
-	go consumer.Start(ctx, cfg.KafkaBrokers, cfg.KafkaTopic, orderService)
+	// Состояние брокеров проверяется при каждом запросе /readyz; регистрируем до запуска,
+	// чтобы /readyz сразу учитывал Kafka
+	healthRegistry.AddCheck("kafka", consumer.KafkaCheck(cfg.KafkaBrokers, cfg.KafkaTopic))
+	kafkaHealth := healthRegistry.Component("kafka")
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		runConsumer(ctx, func(ctx context.Context) error {
+			return consumer.Start(ctx, consumer.Config{
+				Brokers:     cfg.KafkaBrokers,
+				Topic:       cfg.KafkaTopic,
+				DLQTopic:    cfg.KafkaDLQTopic,
+				CommitMode:  consumer.CommitMode(cfg.KafkaCommitMode),
+				Workers:     cfg.KafkaWorkers,
+				KeyBy:       consumer.KeyMode(cfg.KafkaKeyBy),
+				BatchSize:   cfg.KafkaBatchSize,
+				BatchWindow: cfg.KafkaBatchWindow,
+				Health:      kafkaHealth,
+				Validator:   validator,
+				Retry: consumer.RetryPolicy{
+					MaxAttempts: cfg.SaveRetryMaxAttempts,
+					BaseDelay:   cfg.SaveRetryBaseDelay,
+					MaxDelay:    cfg.SaveRetryMaxDelay,
+					Jitter:      cfg.SaveRetryJitter,
+					IsRetryable: database.IsTransient,
+				},
+			}, orderService)
+		}, kafkaHealth, healthRegistry, fail)
+	}()
+
+	// Запускаем веб-сервер; если он не смог запуститься, завершаем приложение
+	webServer := server.New(orderService, healthRegistry, server.Options{
+		ReadTimeout:    cfg.HTTPReadTimeout,
+		WriteTimeout:   cfg.HTTPWriteTimeout,
+		IdleTimeout:    cfg.HTTPIdleTimeout,
+		RequestTimeout: cfg.HTTPRequestTimeout,
+
+		StreamBuffer:       cfg.StreamBuffer,
+		StreamWriteTimeout: cfg.StreamWriteTimeout,
+		StreamHeartbeat:    cfg.StreamHeartbeat,
+	})
+	go func() {
+		if err := webServer.Start(cfg.ServerAddr); err != nil {
+			fail(err)
+		}
+	}()
 
-	// Запускаем веб-сервер
-	webServer := server.New(orderService)
-	if err := webServer.Start(cfg.ServerAddr); err != nil {
-		log.Printf("Ошибка сервера: %v", err)
+	// gRPC-сервер для внутренних потребителей
+	var grpcServer *rpc.Server
+	if cfg.GRPCAddr != "" {
+		grpcServer = rpc.New(orderService, healthRegistry, rpc.Options{RequestTimeout: cfg.HTTPRequestTimeout})
+		go func() {
+			if err := grpcServer.Start(cfg.GRPCAddr); err != nil {
+				fail(err)
+			}
+		}()
 	}
 
 	<-ctx.Done()
-	log.Println("Приложение успешно завершило работу.")
+	shutdown(cfg.ShutdownTimeout, consumerDone, webServer, grpcServer, orderService)
+	select {
+	case <-failed:
+		log.Println("Приложение завершило работу после критической ошибки.")
+		os.Exit(1)
+	default:
+		log.Println("Приложение успешно завершило работу.")
+	}
+}
+
+// shutdown останавливает компоненты по порядку: дожидается остановки consumer'а
+// (он останавливается по отмене корневого контекста), завершает текущие HTTP- и
+// gRPC-запросы, затем останавливает кэш и закрывает пул соединений с БД. Вся остановка
+// ограничена timeout. grpcServer равен nil, если gRPC отключен.
+func shutdown(timeout time.Duration, consumerDone <-chan struct{}, webServer *server.Server, grpcServer *rpc.Server, orderService *database.Service) {
+	log.Printf("Остановка сервиса (не дольше %v)...", timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// 1. Consumer: дожидаемся обработки и фиксации текущих сообщений
+	select {
+	case <-consumerDone:
+		log.Println("Consumer остановлен")
+	case <-ctx.Done():
+		log.Println("Consumer не остановился за отведенное время")
+	}
+
+	// 2. HTTP: перестаем принимать соединения и дожидаемся текущих запросов
+	if err := webServer.Shutdown(ctx); err != nil {
+		log.Printf("Ошибка остановки веб-сервера: %v", err)
+	}
+
+	// 3. gRPC: завершаем потоки WatchOrders и дожидаемся текущих вызовов
+	if grpcServer != nil {
+		if err := grpcServer.Shutdown(ctx); err != nil {
+			log.Printf("Ошибка остановки gRPC-сервера: %v", err)
+		}
+	}
+
+	// 4. Кэш и БД: закрываем после того, как ими перестали пользоваться
+	orderService.Close()
+	log.Println("Кэш остановлен, соединения с БД закрыты")
+}
+
+// registerCacheMetrics публикует статистику кэша сервиса в метриках.
+func registerCacheMetrics(svc *database.Service) {
+	if _, ok := svc.CacheStats(); !ok {
+		return
+	}
+	stat := func(field func(database.CacheStats) float64) func() float64 {
+		return func() float64 {
+			stats, _ := svc.CacheStats()
+			return field(stats)
+		}
+	}
+
+	metrics.Default.NewGaugeFunc("orders_cache_entries", "Число заказов в кэше.",
+		stat(func(s database.CacheStats) float64 { return float64(s.Size) }))
+	metrics.Default.NewGaugeFunc("orders_cache_bytes", "Примерный размер заказов в кэше в байтах.",
+		stat(func(s database.CacheStats) float64 { return float64(s.Bytes) }))
+	metrics.Default.NewCounterFunc("orders_cache_hits_total", "Обращения к кэшу, для которых заказ найден.",
+		stat(func(s database.CacheStats) float64 { return float64(s.Hits) }))
+	metrics.Default.NewCounterFunc("orders_cache_misses_total", "Обращения к кэшу, для которых заказ не найден.",
+		stat(func(s database.CacheStats) float64 { return float64(s.Misses) }))
+	metrics.Default.NewCounterFunc("orders_cache_expirations_total", "Записи кэша, удаленные по TTL.",
+		stat(func(s database.CacheStats) float64 { return float64(s.Expirations) }))
+	metrics.Default.NewCounterFunc("orders_cache_evictions_total", "Записи кэша, вытесненные из-за ограничения размера.",
+		stat(func(s database.CacheStats) float64 { return float64(s.Evictions) }))
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"l1/internal/config"
+	"l1/internal/database"
+	"l1/internal/migrate"
+)
+
+// runMigrate выполняет команду migrate:
+//
+//	migrate [up]     — применить все новые миграции
+//	migrate down [N] — откатить N последних миграций (по умолчанию одну)
+//	migrate status   — показать примененные и ожидающие миграции
+func runMigrate(cfg *config.Config, args []string) error {
+	cmd := "up"
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	store, err := database.NewPostgresStore(cfg.PostgresURL, database.StoreOptions{})
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	m, err := migrate.New(store.DB)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch cmd {
+	case "up":
+		return migrateUp(ctx, m)
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps <= 0 {
+				return fmt.Errorf("число откатываемых миграций должно быть положительным целым, получено %q", args[0])
+			}
+		}
+		rolledBack, err := m.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		for _, mig := range rolledBack {
+			log.Printf("Откачена миграция %04d_%s", mig.Version, mig.Name)
+		}
+		if len(rolledBack) == 0 {
+			log.Println("Нет примененных миграций")
+		}
+		return nil
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "ожидает"
+			if s.Applied() {
+				state = "применена " + s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf("неизвестная команда migrate %q: ожидается up, down или status", cmd)
+	}
+}
+
+// migrateUp применяет новые миграции и сообщает о них в лог.
+func migrateUp(ctx context.Context, m *migrate.Migrator) error {
+	applied, err := m.Up(ctx)
+	if err != nil {
+		return err
+	}
+	for _, mig := range applied {
+		log.Printf("Применена миграция %04d_%s", mig.Version, mig.Name)
+	}
+	if len(applied) == 0 {
+		log.Println("Схема БД актуальна, новых миграций нет")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"l1/internal/model"
+	"l1/internal/validation"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// processBatch обрабатывает пачку сообщений: валидные заказы сохраняются одной операцией
+// SaveOrders, а невалидные и не сохранившиеся обрабатываются как одиночные сообщения.
+// Возвращает для каждого сообщения признак того, что его смещение можно фиксировать.
+func processBatch(ctx context.Context, msgs []kafka.Message, dlq DeadLetterPublisher, store OrderSaver, saver BatchSaver, validator validation.Validator, retry RetryPolicy) []bool {
+	handled := make([]bool, len(msgs))
+
+	orders := make([]model.OrderData, 0, len(msgs))
+	positions := make([]int, 0, len(msgs)) // индекс сообщения для каждого заказа из orders
+	for i, msg := range msgs {
+		received(msg)
+		order, err := decodeOrder(msg.Value, validator)
+		if err != nil {
+			handled[i] = handleFailure(ctx, dlq, msg, err)
+			continue
+		}
+		orders = append(orders, order)
+		positions = append(positions, i)
+	}
+	if len(orders) == 0 {
+		return handled
+	}
+
+	var errs []error
+	err := retry.Do(ctx, func(ctx context.Context) error {
+		var err error
+		errs, err = saver.SaveOrders(ctx, orders)
+		return err
+	})
+	if err != nil {
+		// Пачка не сохранилась даже после повторов — повторять по одному бессмысленно
+		for j, i := range positions {
+			procErr := &ProcessingError{Stage: StageSave, Err: fmt.Errorf("ошибка сохранения пачки заказов в БД (%s): %w", orders[j].OrderUID, err)}
+			handled[i] = handleFailure(ctx, dlq, msgs[i], procErr)
+		}
+		return handled
+	}
+
+	saved := 0
+	for j, i := range positions {
+		orderErr := errs[j]
+		switch {
+		case orderErr == nil:
+			handled[i] = true
+			saved++
+		case retry.IsRetryable != nil && retry.IsRetryable(orderErr):
+			// Временная ошибка отдельного заказа — сохраняем его отдельно с повторами
+			if err := saveOrder(ctx, orders[j], store, retry); err != nil {
+				handled[i] = handleFailure(ctx, dlq, msgs[i], err)
+			} else {
+				handled[i] = true
+			}
+		default:
+			procErr := &ProcessingError{Stage: StageSave, Err: fmt.Errorf("ошибка сохранения заказа в БД (%s): %w", orders[j].OrderUID, orderErr)}
+			handled[i] = handleFailure(ctx, dlq, msgs[i], procErr)
+		}
+	}
+	log.Printf("Пачка обработана: сохранено %d из %d сообщений", saved, len(msgs))
+
+	return handled
+}
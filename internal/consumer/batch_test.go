@@ -0,0 +1,92 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"l1/internal/model"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBatchSaver — мок для OrderSaver с поддержкой пачек.
+type MockBatchSaver struct {
+	MockSaver
+}
+
+func (m *MockBatchSaver) SaveOrders(ctx context.Context, orders []model.OrderData) ([]error, error) {
+	args := m.Called(ctx, orders)
+	errs, _ := args.Get(0).([]error)
+	return errs, args.Error(1)
+}
+
+// TestProcessBatch_PerOrderResults проверяет, что битые сообщения и отклоненные заказы
+// уходят в DLQ, а остальные сохраняются одной пачкой
+func TestProcessBatch_PerOrderResults(t *testing.T) {
+	// --- Arrange ---
+	msgs := []kafka.Message{
+		orderMessage(t, 0, 1, "uid-1"),
+		{Partition: 0, Offset: 2, Value: []byte("{not json")},
+		orderMessage(t, 0, 3, "uid-3"),
+	}
+	conflict := errors.New("conflict")
+
+	store := new(MockBatchSaver)
+	store.On("SaveOrders", mock.Anything, mock.MatchedBy(func(orders []model.OrderData) bool {
+		return len(orders) == 2 && orders[0].OrderUID == "uid-1" && orders[1].OrderUID == "uid-3"
+	})).Return([]error{nil, conflict}, nil).Once()
+
+	dlq := new(MockDeadLetter)
+	dlq.On("Publish", mock.Anything, msgs[1], StageParse, mock.Anything).Return(nil).Once()
+	dlq.On("Publish", mock.Anything, msgs[2], StageSave, mock.Anything).Return(nil).Once()
+
+	// --- Act ---
+	handled := processBatch(context.Background(), msgs, dlq, store, store, nil, RetryPolicy{})
+
+	// --- Assert ---
+	assert.Equal(t, []bool{true, true, true}, handled)
+	store.AssertExpectations(t)
+	store.AssertNotCalled(t, "SaveOrder", mock.Anything, mock.Anything)
+	dlq.AssertExpectations(t)
+}
+
+// TestProcessBatch_RetriesTransientOrderSingly проверяет, что заказ с временной ошибкой
+// сохраняется повторно отдельно
+func TestProcessBatch_RetriesTransientOrderSingly(t *testing.T) {
+	// --- Arrange ---
+	msgs := []kafka.Message{orderMessage(t, 0, 1, "uid-1"), orderMessage(t, 0, 2, "uid-2")}
+	transient := errors.New("deadlock")
+
+	store := new(MockBatchSaver)
+	store.On("SaveOrders", mock.Anything, mock.Anything).Return([]error{transient, nil}, nil).Once()
+	store.On("SaveOrder", mock.Anything, mock.MatchedBy(func(o model.OrderData) bool {
+		return o.OrderUID == "uid-1"
+	})).Return(nil).Once()
+
+	retry := RetryPolicy{MaxAttempts: 2, IsRetryable: func(err error) bool { return errors.Is(err, transient) }}
+
+	// --- Act ---
+	handled := processBatch(context.Background(), msgs, nil, store, store, nil, retry)
+
+	// --- Assert ---
+	assert.Equal(t, []bool{true, true}, handled)
+	store.AssertExpectations(t)
+}
+
+// TestProcessBatch_BatchFailure проверяет, что без DLQ несохраненная пачка не фиксируется
+func TestProcessBatch_BatchFailure(t *testing.T) {
+	// --- Arrange ---
+	msgs := []kafka.Message{orderMessage(t, 0, 1, "uid-1"), orderMessage(t, 0, 2, "uid-2")}
+	store := new(MockBatchSaver)
+	store.On("SaveOrders", mock.Anything, mock.Anything).Return(nil, errors.New("db down")).Once()
+
+	// --- Act ---
+	handled := processBatch(context.Background(), msgs, nil, store, store, nil, RetryPolicy{})
+
+	// --- Assert ---
+	assert.Equal(t, []bool{false, false}, handled)
+	store.AssertExpectations(t)
+}
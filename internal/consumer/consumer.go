@@ -3,11 +3,16 @@ package consumer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"time"
 
+	"l1/internal/health"
+	"l1/internal/metrics"
 	"l1/internal/model"
+	"l1/internal/validation"
 
 	"github.com/segmentio/kafka-go"
 )
@@ -17,35 +22,176 @@ type OrderSaver interface {
 	SaveOrder(ctx context.Context, order model.OrderData) error
 }
 
+// Config описывает параметры consumer'а.
+type Config struct {
+	Brokers  []string
+	Topic    string
+	DLQTopic string      // dead-letter топик; пустая строка отключает DLQ
+	Retry    RetryPolicy // повторы сохранения при временных ошибках БД
+
+	CommitMode CommitMode // когда фиксировать смещение
+
+	Workers int     // число параллельных обработчиков; <= 1 — последовательная обработка
+	KeyBy   KeyMode // как сообщения распределяются по обработчикам
+
+	// Накопление пачек: заказы сохраняются одной операцией, когда набралось BatchSize
+	// сообщений или прошло BatchWindow с первого сообщения пачки.
+	// Работает, если хранилище реализует BatchSaver.
+	BatchSize   int
+	BatchWindow time.Duration
+
+	Health *health.Component // куда сообщать о состоянии подключения к Kafka, может быть nil
+
+	Validator validation.Validator // правила проверки заказа; nil — встроенные правила validation.Default
+}
+
+// CommitMode определяет стратегию фиксации смещений.
+type CommitMode string
+
+const (
+	// CommitAuto — смещение фиксируется при чтении (at-most-once).
+	CommitAuto CommitMode = "auto"
+	// CommitExplicit — смещение фиксируется только после сохранения заказа
+	// или отправки его в DLQ (at-least-once).
+	CommitExplicit CommitMode = "explicit"
+)
+
+// Stage — этап обработки сообщения, на котором произошла ошибка.
+type Stage string
+
+const (
+	StageParse    Stage = "parse"
+	StageValidate Stage = "validate"
+	StageSave     Stage = "save"
+)
+
+// ProcessingError — ошибка обработки сообщения с указанием этапа.
+type ProcessingError struct {
+	Stage Stage
+	Err   error
+}
+
+func (e *ProcessingError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProcessingError) Unwrap() error {
+	return e.Err
+}
+
+// BatchSaver определяет интерфейс для сохранения пачки заказов.
+// Возвращает ошибки по каждому заказу и общую ошибку, если пачка не сохранена целиком.
+type BatchSaver interface {
+	SaveOrders(ctx context.Context, orders []model.OrderData) ([]error, error)
+}
+
 // handleMessage распаковывает, валидирует и сохраняет заказ в хранилище.
-func handleMessage(ctx context.Context, msgValue []byte, store OrderSaver) error {
+// Сохранение повторяется согласно retry, пока ошибка временная.
+func handleMessage(ctx context.Context, msgValue []byte, store OrderSaver, validator validation.Validator, retry RetryPolicy) error {
+	orderMsg, err := decodeOrder(msgValue, validator)
+	if err != nil {
+		return err
+	}
+	return saveOrder(ctx, orderMsg, store, retry)
+}
+
+// decodeOrder распаковывает и валидирует заказ. Если validator не задан,
+// применяются встроенные правила.
+func decodeOrder(msgValue []byte, validator validation.Validator) (model.OrderData, error) {
 	var orderMsg model.OrderData
 	if err := json.Unmarshal(msgValue, &orderMsg); err != nil {
-		return fmt.Errorf("ошибка парсинга JSON: %w", err)
+		return orderMsg, &ProcessingError{Stage: StageParse, Err: fmt.Errorf("ошибка парсинга JSON: %w", err)}
 	}
 
-	if err := orderMsg.Validate(); err != nil {
-		return fmt.Errorf("ошибка валидации заказа (%s): %w", orderMsg.OrderUID, err)
+	if validator == nil {
+		validator = validation.Default()
 	}
+	if err := validator.Validate(&orderMsg); err != nil {
+		return orderMsg, &ProcessingError{Stage: StageValidate, Err: fmt.Errorf("ошибка валидации заказа (%s): %w", orderMsg.OrderUID, err)}
+	}
+	return orderMsg, nil
+}
 
-	if err := store.SaveOrder(ctx, orderMsg); err != nil {
-		return fmt.Errorf("ошибка сохранения заказа в БД (%s): %w", orderMsg.OrderUID, err)
+// saveOrder сохраняет заказ, повторяя попытки согласно retry.
+func saveOrder(ctx context.Context, orderMsg model.OrderData, store OrderSaver, retry RetryPolicy) error {
+	err := retry.Do(ctx, func(ctx context.Context) error {
+		return store.SaveOrder(ctx, orderMsg)
+	})
+	if err != nil {
+		return &ProcessingError{Stage: StageSave, Err: fmt.Errorf("ошибка сохранения заказа в БД (%s): %w", orderMsg.OrderUID, err)}
 	}
 
 	log.Printf("Заказ %s успешно сохранен", orderMsg.OrderUID)
 	return nil
 }
 
-// Start запускает consumer'а Kafka.
-func Start(ctx context.Context, brokers []string, topic string, store OrderSaver) {
-	r := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:        brokers,
-		Topic:          topic,
+// deadLetter отправляет сообщение в DLQ, если он настроен.
+// Возвращает true, если сообщение опубликовано.
+func deadLetter(ctx context.Context, dlq DeadLetterPublisher, msg kafka.Message, cause error) bool {
+	if dlq == nil {
+		return false
+	}
+
+	stage := stageOf(cause)
+	if err := dlq.Publish(ctx, msg, stage, cause); err != nil {
+		log.Printf("ошибка отправки сообщения offset=%d в DLQ: %v", msg.Offset, err)
+		return false
+	}
+	metrics.ConsumerDeadLetters.With(string(stage)).Inc()
+	log.Printf("Сообщение offset=%d отправлено в DLQ (этап: %s)", msg.Offset, stage)
+	return true
+}
+
+// stageOf возвращает этап, на котором произошла ошибка. Ошибки без этапа
+// относятся к сохранению.
+func stageOf(err error) Stage {
+	var procErr *ProcessingError
+	if errors.As(err, &procErr) {
+		return procErr.Stage
+	}
+	return StageSave
+}
+
+// isPermanentInputError сообщает, что сообщение невозможно обработать в принципе
+// (битый JSON или невалидный заказ), и повторная доставка ничего не изменит.
+func isPermanentInputError(err error) bool {
+	var procErr *ProcessingError
+	return errors.As(err, &procErr) && (procErr.Stage == StageParse || procErr.Stage == StageValidate)
+}
+
+// messageReader — подмножество методов kafka.Reader, которое использует consumer.
+type messageReader interface {
+	ReadMessage(ctx context.Context) (kafka.Message, error)
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// commitTimeout ограничивает фиксацию смещения, которая выполняется и после отмены контекста,
+// чтобы уже сохраненный заказ не был доставлен повторно.
+const commitTimeout = 5 * time.Second
+
+// Start запускает consumer'а Kafka. Возвращает ошибку, если consumer был остановлен
+// из-за сообщения, которое не удалось ни сохранить, ни отправить в DLQ.
+func Start(ctx context.Context, cfg Config, store OrderSaver) error {
+	if cfg.CommitMode != CommitExplicit {
+		cfg.CommitMode = CommitAuto
+	}
+	if cfg.KeyBy != KeyOrderUID {
+		cfg.KeyBy = KeyPartition
+	}
+
+	readerCfg := kafka.ReaderConfig{
+		Brokers:        cfg.Brokers,
+		Topic:          cfg.Topic,
 		GroupID:        "order-processor-group", // Группа консьюмеров
 		MinBytes:       10e3,                    // 10KB
 		MaxBytes:       10e6,                    // 10MB
 		CommitInterval: time.Second,             // Фиксируем смещение каждую секунду
-	})
+	}
+	if cfg.CommitMode == CommitExplicit {
+		readerCfg.CommitInterval = 0 // CommitMessages фиксирует смещение синхронно
+	}
+	r := kafka.NewReader(readerCfg)
 	defer func(r *kafka.Reader) {
 		err := r.Close()
 		if err != nil {
@@ -53,26 +199,159 @@ func Start(ctx context.Context, brokers []string, topic string, store OrderSaver
 		}
 	}(r)
 
-	log.Printf("Запущен consumer для топика '%s'", topic)
+	var dlq DeadLetterPublisher
+	if cfg.DLQTopic != "" {
+		dlq = NewKafkaDeadLetter(cfg.Brokers, cfg.DLQTopic)
+		defer func() {
+			if err := dlq.Close(); err != nil {
+				log.Printf("ошибка закрытия DLQ writer: %v", err)
+			}
+		}()
+	}
+
+	if err := probeKafka(ctx, cfg.Brokers, cfg.Topic); err != nil {
+		log.Printf("Kafka недоступна: %v", err)
+		cfg.Health.SetError(err)
+	} else {
+		cfg.Health.SetReady()
+	}
+
+	log.Printf("Запущен consumer для топика '%s' (режим фиксации: %s)", cfg.Topic, cfg.CommitMode)
+
+	err := run(ctx, r, dlq, store, cfg)
+
+	log.Println("Consumer остановлен.")
+	return err
+}
+
+// probeTimeout ограничивает проверку подключения к Kafka.
+const probeTimeout = 5 * time.Second
+
+// KafkaCheck возвращает проверку готовности Kafka для health.Registry.AddCheck.
+// kafka-go повторяет ошибки брокеров внутри FetchMessage, не возвращая их, поэтому
+// по результатам чтения потерю брокеров не заметить: состояние Kafka определяется
+// подключением к брокерам при каждом запросе готовности.
+func KafkaCheck(brokers []string, topic string) health.CheckFunc {
+	return func(ctx context.Context) error {
+		return probeKafka(ctx, brokers, topic)
+	}
+}
+
+// probeKafka проверяет, что хотя бы один брокер доступен и знает о топике.
+func probeKafka(ctx context.Context, brokers []string, topic string) error {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	var errs []error
+	for _, broker := range brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		_, err = conn.ReadPartitions(topic)
+		_ = conn.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("не удалось получить партиции топика %s у %s: %w", topic, broker, err))
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("нет доступных брокеров Kafka: %w", errors.Join(errs...))
+}
+
+// reportFetch сообщает о результате чтения из Kafka в health.
+func reportFetch(ctx context.Context, component *health.Component, err error) {
+	switch {
+	case err == nil:
+		component.SetReady()
+	case ctx.Err() == nil:
+		component.SetError(fmt.Errorf("ошибка чтения из Kafka: %w", err))
+	}
+}
+
+// run — основной цикл чтения и обработки сообщений.
+func run(ctx context.Context, r messageReader, dlq DeadLetterPublisher, store OrderSaver, cfg Config) error {
+	if cfg.Workers > 1 || cfg.BatchSize > 1 {
+		return runPool(ctx, r, dlq, store, cfg)
+	}
+
+	explicit := cfg.CommitMode == CommitExplicit
 
 	for {
-		// Читаем сообщение из Kafka
-		msg, err := r.ReadMessage(ctx)
+		msg, err := fetch(ctx, r, explicit)
+		reportFetch(ctx, cfg.Health, err)
 		if err != nil {
 			// Если контекст отменен, выходим из цикла
 			if ctx.Err() != nil {
-				break
+				return nil
 			}
 			log.Printf("Ошибка при чтении сообщения: %v", err)
 			continue
 		}
 
-		log.Printf("Получено сообщение: offset=%d, key=%s, value=%s\n", msg.Offset, string(msg.Key), string(msg.Value))
+		handled := process(ctx, msg, dlq, store, cfg.Validator, cfg.Retry)
 
-		if err := handleMessage(ctx, msg.Value, store); err != nil {
-			log.Printf("ошибка обработки сообщения: %v", err)
+		if !explicit {
+			continue
+		}
+		if !handled {
+			if ctx.Err() != nil {
+				return nil // смещение не зафиксировано, сообщение будет доставлено повторно
+			}
+			return notHandledError(msg)
+		}
+		if err := commit(ctx, r, msg); err != nil {
+			log.Printf("Ошибка фиксации смещения partition=%d offset=%d: %v", msg.Partition, msg.Offset, err)
 		}
 	}
+}
 
-	log.Println("Consumer остановлен.")
+// fetch читает очередное сообщение. В явном режиме смещение не фиксируется при чтении.
+func fetch(ctx context.Context, r messageReader, explicit bool) (kafka.Message, error) {
+	if explicit {
+		return r.FetchMessage(ctx)
+	}
+	return r.ReadMessage(ctx)
+}
+
+// process обрабатывает одно сообщение. Возвращает false, если сообщение не удалось
+// ни сохранить, ни отправить в DLQ, и фиксировать его смещение нельзя.
+func process(ctx context.Context, msg kafka.Message, dlq DeadLetterPublisher, store OrderSaver, validator validation.Validator, retry RetryPolicy) bool {
+	received(msg)
+
+	if err := handleMessage(ctx, msg.Value, store, validator, retry); err != nil {
+		return handleFailure(ctx, dlq, msg, err)
+	}
+	return true
+}
+
+// handleFailure обрабатывает сообщение, которое не удалось сохранить: отправляет его в DLQ.
+// Возвращает true, если смещение сообщения можно фиксировать.
+func handleFailure(ctx context.Context, dlq DeadLetterPublisher, msg kafka.Message, err error) bool {
+	log.Printf("ошибка обработки сообщения: %v", err)
+	metrics.ConsumerFailures.With(string(stageOf(err))).Inc()
+	return deadLetter(ctx, dlq, msg, err) || (dlq == nil && isPermanentInputError(err))
+}
+
+// received пишет в лог полученное сообщение и учитывает его в метриках.
+func received(msg kafka.Message) {
+	metrics.ConsumerMessages.With().Inc()
+	// HighWaterMark — смещение следующего сообщения, которое будет записано в партицию
+	if msg.HighWaterMark > 0 {
+		metrics.ConsumerLag.With(strconv.Itoa(msg.Partition)).Set(float64(max(msg.HighWaterMark-msg.Offset-1, 0)))
+	}
+	log.Printf("Получено сообщение: partition=%d, offset=%d, key=%s, value=%s\n", msg.Partition, msg.Offset, string(msg.Key), string(msg.Value))
+}
+
+// notHandledError — причина остановки consumer'а в явном режиме фиксации.
+func notHandledError(msg kafka.Message) error {
+	return fmt.Errorf("сообщение partition=%d offset=%d не сохранено и не отправлено в DLQ, смещение не зафиксировано", msg.Partition, msg.Offset)
+}
+
+// commit фиксирует смещение обработанного сообщения.
+func commit(ctx context.Context, r messageReader, msgs ...kafka.Message) error {
+	commitCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), commitTimeout)
+	defer cancel()
+	return r.CommitMessages(commitCtx, msgs...)
 }
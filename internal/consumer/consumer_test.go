@@ -0,0 +1,351 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"l1/internal/health"
+	"l1/internal/model"
+	"l1/internal/validation"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// --- Моки ---
+
+// MockSaver — мок для интерфейса OrderSaver.
+type MockSaver struct {
+	mock.Mock
+}
+
+func (m *MockSaver) SaveOrder(ctx context.Context, order model.OrderData) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+// MockDeadLetter — мок для интерфейса DeadLetterPublisher.
+type MockDeadLetter struct {
+	mock.Mock
+}
+
+func (m *MockDeadLetter) Publish(ctx context.Context, msg kafka.Message, stage Stage, cause error) error {
+	args := m.Called(ctx, msg, stage, cause)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetter) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// fakeReader — простая реализация messageReader поверх среза сообщений.
+// Когда сообщения заканчиваются, отменяет контекст consumer'а.
+type fakeReader struct {
+	msgs      []kafka.Message
+	cancel    context.CancelFunc
+	committed []kafka.Message
+	fetched   int
+	read      int
+}
+
+func (f *fakeReader) next(ctx context.Context) (kafka.Message, error) {
+	if len(f.msgs) == 0 {
+		f.cancel()
+		return kafka.Message{}, ctx.Err()
+	}
+	msg := f.msgs[0]
+	f.msgs = f.msgs[1:]
+	return msg, nil
+}
+
+func (f *fakeReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+	f.read++
+	return f.next(ctx)
+}
+
+func (f *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	f.fetched++
+	return f.next(ctx)
+}
+
+func (f *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	f.committed = append(f.committed, msgs...)
+	return nil
+}
+
+// loadTestOrderJSON читает тестовый заказ из JSON-файла.
+func loadTestOrderJSON(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile("../../internal/test/test_model.json")
+	require.NoError(t, err)
+	return data
+}
+
+// --- Тесты ---
+
+// TestHandleMessage_Success проверяет успешную обработку сообщения
+func TestHandleMessage_Success(t *testing.T) {
+	store := new(MockSaver)
+	store.On("SaveOrder", mock.Anything, mock.AnythingOfType("model.OrderData")).Return(nil).Once()
+
+	err := handleMessage(context.Background(), loadTestOrderJSON(t), store, nil, RetryPolicy{})
+
+	require.NoError(t, err)
+	store.AssertExpectations(t)
+}
+
+// TestHandleMessage_Stages проверяет, что ошибка помечается правильным этапом обработки
+func TestHandleMessage_Stages(t *testing.T) {
+	valid := loadTestOrderJSON(t)
+
+	var invalidOrder map[string]any
+	require.NoError(t, json.Unmarshal(valid, &invalidOrder))
+	invalidOrder["track_number"] = ""
+	invalid, err := json.Marshal(invalidOrder)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		payload []byte
+		saveErr error
+		stage   Stage
+	}{
+		{name: "битый JSON", payload: []byte("{not json"), stage: StageParse},
+		{name: "невалидный заказ", payload: invalid, stage: StageValidate},
+		{name: "ошибка БД", payload: valid, saveErr: errors.New("db down"), stage: StageSave},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := new(MockSaver)
+			store.On("SaveOrder", mock.Anything, mock.Anything).Return(tt.saveErr).Maybe()
+
+			err := handleMessage(context.Background(), tt.payload, store, nil, RetryPolicy{})
+
+			var procErr *ProcessingError
+			require.ErrorAs(t, err, &procErr)
+			assert.Equal(t, tt.stage, procErr.Stage)
+			if tt.saveErr != nil {
+				assert.ErrorIs(t, err, tt.saveErr)
+			}
+		})
+	}
+}
+
+// TestHandleMessage_CustomValidator проверяет, что заказ проверяется заданными правилами
+// и ошибка содержит все нарушения
+func TestHandleMessage_CustomValidator(t *testing.T) {
+	rules, err := validation.Parse([]byte(`rules: [{field: payment.currency, enum: [KZT]}, {field: delivery.zip, pattern: '^\d{6}$'}]`))
+	require.NoError(t, err)
+	store := new(MockSaver)
+
+	err = handleMessage(context.Background(), loadTestOrderJSON(t), store, rules, RetryPolicy{})
+
+	var procErr *ProcessingError
+	require.ErrorAs(t, err, &procErr)
+	assert.Equal(t, StageValidate, procErr.Stage)
+	var errs validation.ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 2)
+	store.AssertNotCalled(t, "SaveOrder", mock.Anything, mock.Anything)
+}
+
+// TestDeadLetterMessage проверяет заголовки сообщения для DLQ
+func TestDeadLetterMessage(t *testing.T) {
+	src := kafka.Message{
+		Topic:     "orders",
+		Partition: 2,
+		Offset:    42,
+		Key:       []byte("key"),
+		Value:     []byte("value"),
+		Headers:   []kafka.Header{{Key: "trace-id", Value: []byte("abc")}},
+	}
+	now := time.Date(2025, 10, 26, 8, 22, 19, 0, time.UTC)
+
+	msg := deadLetterMessage(src, StageValidate, errors.New("bad order"), now)
+
+	assert.Equal(t, src.Key, msg.Key)
+	assert.Equal(t, src.Value, msg.Value)
+
+	headers := make(map[string]string)
+	for _, h := range msg.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	assert.Equal(t, "abc", headers["trace-id"], "исходные заголовки должны сохраняться")
+	assert.Equal(t, "validate", headers[HeaderDLQStage])
+	assert.Equal(t, "bad order", headers[HeaderDLQError])
+	assert.Equal(t, "orders", headers[HeaderDLQSourceTopic])
+	assert.Equal(t, "2", headers[HeaderDLQSourcePartition])
+	assert.Equal(t, "42", headers[HeaderDLQSourceOffset])
+	assert.Equal(t, "2025-10-26T08:22:19Z", headers[HeaderDLQTimestamp])
+}
+
+// TestDeadLetter_UsesErrorStage проверяет, что в DLQ передается этап из ProcessingError
+func TestDeadLetter_UsesErrorStage(t *testing.T) {
+	dlq := new(MockDeadLetter)
+	msg := kafka.Message{Offset: 7}
+	cause := &ProcessingError{Stage: StageParse, Err: errors.New("bad json")}
+	dlq.On("Publish", mock.Anything, msg, StageParse, cause).Return(nil).Once()
+
+	deadLetter(context.Background(), dlq, msg, cause)
+
+	dlq.AssertExpectations(t)
+}
+
+// TestDeadLetter_Disabled проверяет, что без DLQ ничего не происходит
+func TestDeadLetter_Disabled(t *testing.T) {
+	assert.NotPanics(t, func() {
+		deadLetter(context.Background(), nil, kafka.Message{}, errors.New("any"))
+	})
+}
+
+// TestRun_ExplicitCommitAfterSave проверяет, что смещение фиксируется после сохранения
+func TestRun_ExplicitCommitAfterSave(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	msg := kafka.Message{Partition: 1, Offset: 10, Value: loadTestOrderJSON(t)}
+	reader := &fakeReader{msgs: []kafka.Message{msg}, cancel: cancel}
+	store := new(MockSaver)
+	store.On("SaveOrder", mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := run(ctx, reader, nil, store, Config{CommitMode: CommitExplicit})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, reader.read, "в явном режиме не должен использоваться ReadMessage")
+	require.Len(t, reader.committed, 1)
+	assert.Equal(t, int64(10), reader.committed[0].Offset)
+	store.AssertExpectations(t)
+}
+
+// TestRun_ExplicitCommitAfterDeadLetter проверяет фиксацию смещения после отправки в DLQ
+func TestRun_ExplicitCommitAfterDeadLetter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	msg := kafka.Message{Offset: 11, Value: loadTestOrderJSON(t)}
+	reader := &fakeReader{msgs: []kafka.Message{msg}, cancel: cancel}
+	store := new(MockSaver)
+	store.On("SaveOrder", mock.Anything, mock.Anything).Return(errors.New("db down")).Once()
+	dlq := new(MockDeadLetter)
+	dlq.On("Publish", mock.Anything, msg, StageSave, mock.Anything).Return(nil).Once()
+
+	err := run(ctx, reader, dlq, store, Config{CommitMode: CommitExplicit})
+
+	require.NoError(t, err)
+	require.Len(t, reader.committed, 1)
+	dlq.AssertExpectations(t)
+}
+
+// TestRun_ExplicitNoCommitWhenNotStored проверяет, что без DLQ несохраненный заказ
+// останавливает consumer без фиксации смещения
+func TestRun_ExplicitNoCommitWhenNotStored(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	msgs := []kafka.Message{
+		{Offset: 12, Value: loadTestOrderJSON(t)},
+		{Offset: 13, Value: loadTestOrderJSON(t)},
+	}
+	reader := &fakeReader{msgs: msgs, cancel: cancel}
+	store := new(MockSaver)
+	store.On("SaveOrder", mock.Anything, mock.Anything).Return(errors.New("db down")).Once()
+
+	err := run(ctx, reader, nil, store, Config{CommitMode: CommitExplicit})
+
+	require.Error(t, err)
+	assert.Empty(t, reader.committed)
+	assert.Equal(t, 1, reader.fetched, "после ошибки consumer не должен читать дальше")
+}
+
+// TestRun_ExplicitSkipsInvalidWithoutDLQ проверяет, что без DLQ битое сообщение
+// пропускается, чтобы не блокировать партицию
+func TestRun_ExplicitSkipsInvalidWithoutDLQ(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reader := &fakeReader{msgs: []kafka.Message{{Offset: 14, Value: []byte("{not json")}}, cancel: cancel}
+	store := new(MockSaver)
+
+	err := run(ctx, reader, nil, store, Config{CommitMode: CommitExplicit})
+
+	require.NoError(t, err)
+	require.Len(t, reader.committed, 1)
+	store.AssertNotCalled(t, "SaveOrder", mock.Anything, mock.Anything)
+}
+
+// TestRun_AutoCommit проверяет, что в режиме auto используется ReadMessage без явной фиксации
+func TestRun_AutoCommit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reader := &fakeReader{msgs: []kafka.Message{{Offset: 15, Value: loadTestOrderJSON(t)}}, cancel: cancel}
+	store := new(MockSaver)
+	store.On("SaveOrder", mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := run(ctx, reader, nil, store, Config{CommitMode: CommitAuto})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, reader.fetched)
+	assert.Empty(t, reader.committed)
+}
+
+// errReader — messageReader, который возвращает ошибку чтения, а затем отменяет контекст.
+type errReader struct {
+	fakeReader
+	err error
+}
+
+func (r *errReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if r.err != nil {
+		err := r.err
+		r.err = nil
+		return kafka.Message{}, err
+	}
+	return r.fakeReader.FetchMessage(ctx)
+}
+
+// TestRun_ReportsHealth проверяет, что ошибки чтения и успешное чтение отражаются в health
+func TestRun_ReportsHealth(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	registry := health.NewRegistry()
+	component := registry.Component("kafka")
+	reader := &errReader{
+		fakeReader: fakeReader{msgs: []kafka.Message{{Offset: 16, Value: loadTestOrderJSON(t)}}, cancel: cancel},
+		err:        errors.New("broker unavailable"),
+	}
+	store := new(MockSaver)
+	store.On("SaveOrder", mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := run(ctx, reader, nil, store, Config{CommitMode: CommitExplicit, Health: component})
+
+	require.NoError(t, err)
+	state := registry.Readiness(context.Background()).Components["kafka"]
+	assert.Equal(t, health.StatusOK, state.Status, "после успешного чтения компонент снова готов")
+	assert.Contains(t, state.LastError, "broker unavailable")
+}
+
+// TestKafkaCheck_BrokerLost проверяет, что готовность Kafka определяется подключением
+// к брокерам при запросе, а не только последним чтением
+func TestKafkaCheck_BrokerLost(t *testing.T) {
+	// --- Arrange ---
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	broker := ln.Addr().String()
+	require.NoError(t, ln.Close()) // брокер пропал после старта
+
+	registry := health.NewRegistry()
+	registry.AddCheck("kafka", KafkaCheck([]string{broker}, "orders"))
+	registry.Component("kafka").SetReady()
+
+	// --- Act ---
+	report := registry.Readiness(context.Background())
+
+	// --- Assert ---
+	assert.Equal(t, health.StatusDown, report.Components["kafka"].Status)
+	assert.Contains(t, report.Components["kafka"].LastError, "нет доступных брокеров Kafka")
+}
@@ -0,0 +1,79 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Заголовки, которыми помечается сообщение в dead-letter топике.
+const (
+	HeaderDLQStage           = "dlq-stage"
+	HeaderDLQError           = "dlq-error"
+	HeaderDLQSourceTopic     = "dlq-source-topic"
+	HeaderDLQSourcePartition = "dlq-source-partition"
+	HeaderDLQSourceOffset    = "dlq-source-offset"
+	HeaderDLQTimestamp       = "dlq-timestamp"
+)
+
+// DeadLetterPublisher определяет интерфейс для публикации сообщений,
+// которые не удалось обработать.
+type DeadLetterPublisher interface {
+	Publish(ctx context.Context, msg kafka.Message, stage Stage, cause error) error
+	Close() error
+}
+
+// KafkaDeadLetter публикует необработанные сообщения в отдельный топик Kafka.
+type KafkaDeadLetter struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaDeadLetter создает publisher для dead-letter топика.
+func NewKafkaDeadLetter(brokers []string, topic string) *KafkaDeadLetter {
+	return &KafkaDeadLetter{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{}, // сообщения с одним ключом попадают в одну партицию
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+// Publish отправляет исходное сообщение в dead-letter топик вместе с описанием ошибки.
+func (d *KafkaDeadLetter) Publish(ctx context.Context, msg kafka.Message, stage Stage, cause error) error {
+	if err := d.writer.WriteMessages(ctx, deadLetterMessage(msg, stage, cause, time.Now())); err != nil {
+		return fmt.Errorf("не удалось отправить сообщение в DLQ: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает writer.
+func (d *KafkaDeadLetter) Close() error {
+	return d.writer.Close()
+}
+
+// deadLetterMessage формирует сообщение для DLQ: исходные ключ, значение и заголовки
+// плюс заголовки с этапом, текстом ошибки и координатами исходного сообщения.
+func deadLetterMessage(src kafka.Message, stage Stage, cause error, now time.Time) kafka.Message {
+	headers := make([]kafka.Header, 0, len(src.Headers)+6)
+	headers = append(headers, src.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderDLQStage, Value: []byte(stage)},
+		kafka.Header{Key: HeaderDLQError, Value: []byte(cause.Error())},
+		kafka.Header{Key: HeaderDLQSourceTopic, Value: []byte(src.Topic)},
+		kafka.Header{Key: HeaderDLQSourcePartition, Value: []byte(strconv.Itoa(src.Partition))},
+		kafka.Header{Key: HeaderDLQSourceOffset, Value: []byte(strconv.FormatInt(src.Offset, 10))},
+		kafka.Header{Key: HeaderDLQTimestamp, Value: []byte(now.UTC().Format(time.RFC3339Nano))},
+	)
+
+	return kafka.Message{
+		Key:     src.Key,
+		Value:   src.Value,
+		Headers: headers,
+	}
+}
@@ -0,0 +1,301 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KeyMode определяет, по какому ключу сообщения распределяются между обработчиками.
+// Сообщения с одинаковым ключом всегда обрабатываются одним обработчиком по порядку.
+type KeyMode string
+
+const (
+	// KeyPartition — все сообщения одной партиции попадают к одному обработчику.
+	KeyPartition KeyMode = "partition"
+	// KeyOrderUID — сообщения распределяются по хэшу order_uid, поэтому одна партиция
+	// обрабатывается параллельно, а порядок сохраняется только для одного заказа.
+	KeyOrderUID KeyMode = "order_uid"
+)
+
+// workerQueueSize — размер очереди каждого обработчика.
+const workerQueueSize = 64
+
+// runPool читает сообщения и раздает их cfg.Workers обработчикам по ключу.
+// В явном режиме смещение партиции фиксируется только тогда, когда обработаны
+// все сообщения до него включительно.
+func runPool(ctx context.Context, r messageReader, dlq DeadLetterPublisher, store OrderSaver, cfg Config) error {
+	explicit := cfg.CommitMode == CommitExplicit
+	workers := max(cfg.Workers, 1)
+	poolCtx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+
+	tracker := newOffsetTracker()
+	commits := make(chan kafka.Message, workers*workerQueueSize)
+	committerDone := make(chan struct{})
+	go func() {
+		defer close(committerDone)
+		runCommitter(ctx, r, commits)
+	}()
+
+	w := &worker{
+		ctx:      poolCtx,
+		stop:     stop,
+		dlq:      dlq,
+		store:    store,
+		cfg:      cfg,
+		explicit: explicit,
+		tracker:  tracker,
+		commits:  commits,
+	}
+	if saver, ok := store.(BatchSaver); ok && cfg.BatchSize > 1 {
+		w.batchSaver = saver
+	}
+
+	queues := make([]chan kafka.Message, workers)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan kafka.Message, workerQueueSize)
+		wg.Add(1)
+		go func(queue <-chan kafka.Message) {
+			defer wg.Done()
+			w.run(queue)
+		}(queues[i])
+	}
+
+	log.Printf("Запущено %d обработчиков сообщений (ключ распределения: %s, размер пачки: %d)", workers, cfg.KeyBy, max(cfg.BatchSize, 1))
+
+	for poolCtx.Err() == nil {
+		msg, err := fetch(poolCtx, r, explicit)
+		reportFetch(poolCtx, cfg.Health, err)
+		if err != nil {
+			if poolCtx.Err() != nil {
+				break
+			}
+			log.Printf("Ошибка при чтении сообщения: %v", err)
+			continue
+		}
+
+		if explicit {
+			tracker.track(msg)
+		}
+		queue := queues[workerIndex(msg, cfg.KeyBy, len(queues))]
+		select {
+		case queue <- msg:
+		case <-poolCtx.Done():
+		}
+	}
+
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+	close(commits)
+	<-committerDone
+
+	// Остановка по внешнему контексту — штатное завершение
+	if ctx.Err() != nil {
+		return nil
+	}
+	return context.Cause(poolCtx)
+}
+
+// worker — общие для всех обработчиков пула зависимости и состояние.
+type worker struct {
+	ctx        context.Context
+	stop       context.CancelCauseFunc
+	dlq        DeadLetterPublisher
+	store      OrderSaver
+	batchSaver BatchSaver // nil, если пачки не используются
+	cfg        Config
+	explicit   bool
+	tracker    *offsetTracker
+	commits    chan<- kafka.Message
+}
+
+// run обрабатывает сообщения из очереди, при необходимости накапливая их в пачки.
+func (w *worker) run(queue <-chan kafka.Message) {
+	batchSize := 1
+	if w.batchSaver != nil {
+		batchSize = w.cfg.BatchSize
+	}
+	batch := make([]kafka.Message, 0, batchSize)
+
+	var timer *time.Timer
+	var timerC <-chan time.Time
+	flush := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, timerC = nil, nil
+		}
+		if len(batch) > 0 {
+			w.handle(batch)
+			batch = batch[:0]
+		}
+	}
+	defer flush()
+
+	for {
+		select {
+		case msg, ok := <-queue:
+			if !ok {
+				return
+			}
+			batch = append(batch, msg)
+			switch {
+			case len(batch) >= batchSize:
+				flush()
+			case w.cfg.BatchWindow <= 0 && len(queue) == 0:
+				// Без окна накопления пачка отправляется, как только очередь опустела
+				flush()
+			case len(batch) == 1 && w.cfg.BatchWindow > 0:
+				timer = time.NewTimer(w.cfg.BatchWindow)
+				timerC = timer.C
+			}
+		case <-timerC:
+			timer, timerC = nil, nil
+			flush()
+		}
+	}
+}
+
+// handle обрабатывает пачку сообщений и фиксирует смещения обработанных.
+func (w *worker) handle(batch []kafka.Message) {
+	// После остановки оставшиеся сообщения не обрабатываются и не фиксируются,
+	// поэтому будут доставлены повторно
+	if w.ctx.Err() != nil {
+		return
+	}
+
+	var handled []bool
+	if w.batchSaver != nil && len(batch) > 1 {
+		handled = processBatch(w.ctx, batch, w.dlq, w.store, w.batchSaver, w.cfg.Validator, w.cfg.Retry)
+	} else {
+		handled = make([]bool, len(batch))
+		for i, msg := range batch {
+			handled[i] = process(w.ctx, msg, w.dlq, w.store, w.cfg.Validator, w.cfg.Retry)
+		}
+	}
+
+	if !w.explicit {
+		return
+	}
+	for i, msg := range batch {
+		if !handled[i] {
+			w.stop(notHandledError(msg))
+			continue
+		}
+		if ready, ok := w.tracker.complete(msg); ok {
+			w.commits <- ready
+		}
+	}
+}
+
+// workerIndex выбирает обработчик для сообщения.
+func workerIndex(msg kafka.Message, keyBy KeyMode, workers int) int {
+	if keyBy == KeyOrderUID {
+		var key struct {
+			OrderUID string `json:"order_uid"`
+		}
+		// Если order_uid не извлекается, сообщение все равно упадет на парсинге,
+		// поэтому достаточно распределить его по партиции
+		if err := json.Unmarshal(msg.Value, &key); err == nil && key.OrderUID != "" {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(key.OrderUID))
+			return int(h.Sum32() % uint32(workers))
+		}
+	}
+	return msg.Partition % workers
+}
+
+// runCommitter фиксирует смещения из канала. Несколько готовых смещений одной партиции
+// схлопываются в одно — последнее, а фиксация идет из одной горутины, чтобы более
+// старое смещение не перезаписало более новое.
+func runCommitter(ctx context.Context, r messageReader, commits <-chan kafka.Message) {
+	for msg := range commits {
+		latest := map[int]kafka.Message{msg.Partition: msg}
+	drain:
+		for {
+			select {
+			case next, ok := <-commits:
+				if !ok {
+					break drain
+				}
+				if next.Offset > latest[next.Partition].Offset {
+					latest[next.Partition] = next
+				}
+			default:
+				break drain
+			}
+		}
+
+		msgs := make([]kafka.Message, 0, len(latest))
+		for _, m := range latest {
+			msgs = append(msgs, m)
+		}
+		if err := commit(ctx, r, msgs...); err != nil {
+			log.Printf("Ошибка фиксации смещений: %v", err)
+		}
+	}
+}
+
+// offsetTracker отслеживает обработку сообщений по партициям.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[int]*partitionOffsets
+}
+
+// partitionOffsets — полученные, но еще не зафиксированные сообщения партиции.
+type partitionOffsets struct {
+	pending []int64                 // смещения в порядке получения
+	done    map[int64]kafka.Message // обработанные сообщения из pending
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[int]*partitionOffsets)}
+}
+
+// track регистрирует полученное сообщение.
+func (t *offsetTracker) track(msg kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[msg.Partition]
+	if !ok {
+		p = &partitionOffsets{done: make(map[int64]kafka.Message)}
+		t.partitions[msg.Partition] = p
+	}
+	p.pending = append(p.pending, msg.Offset)
+}
+
+// complete отмечает сообщение обработанным и возвращает сообщение, смещение которого
+// теперь можно зафиксировать: последнее из непрерывной последовательности обработанных
+// с начала очереди партиции.
+func (t *offsetTracker) complete(msg kafka.Message) (kafka.Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[msg.Partition]
+	if !ok {
+		return kafka.Message{}, false
+	}
+	p.done[msg.Offset] = msg
+
+	var ready kafka.Message
+	found := false
+	for len(p.pending) > 0 {
+		m, ok := p.done[p.pending[0]]
+		if !ok {
+			break
+		}
+		delete(p.done, p.pending[0])
+		p.pending = p.pending[1:]
+		ready, found = m, true
+	}
+	return ready, found
+}
@@ -0,0 +1,155 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"l1/internal/model"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingReader отдает сообщения из среза, а затем блокируется до отмены контекста.
+// Безопасен для одновременного чтения и фиксации.
+type blockingReader struct {
+	mu        sync.Mutex
+	msgs      []kafka.Message
+	committed map[int]int64 // последнее зафиксированное смещение по партициям
+}
+
+func (b *blockingReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+	return b.FetchMessage(ctx)
+}
+
+func (b *blockingReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	b.mu.Lock()
+	if len(b.msgs) > 0 {
+		msg := b.msgs[0]
+		b.msgs = b.msgs[1:]
+		b.mu.Unlock()
+		return msg, nil
+	}
+	b.mu.Unlock()
+
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (b *blockingReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, m := range msgs {
+		if last, ok := b.committed[m.Partition]; ok && m.Offset < last {
+			return fmt.Errorf("смещение партиции %d откатилось: %d < %d", m.Partition, m.Offset, last)
+		}
+		b.committed[m.Partition] = m.Offset
+	}
+	return nil
+}
+
+func (b *blockingReader) committedOffsets() map[int]int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	res := make(map[int]int64, len(b.committed))
+	for k, v := range b.committed {
+		res[k] = v
+	}
+	return res
+}
+
+// orderMessage формирует сообщение с валидным заказом для партиции и смещения.
+func orderMessage(t *testing.T, partition int, offset int64, uid string) kafka.Message {
+	t.Helper()
+	var order model.OrderData
+	require.NoError(t, json.Unmarshal(loadTestOrderJSON(t), &order))
+	order.OrderUID = uid
+	value, err := json.Marshal(order)
+	require.NoError(t, err)
+	return kafka.Message{Partition: partition, Offset: offset, Value: value}
+}
+
+// TestOffsetTracker_ContiguousCommit проверяет, что фиксируется только непрерывный префикс
+func TestOffsetTracker_ContiguousCommit(t *testing.T) {
+	tracker := newOffsetTracker()
+	m1 := kafka.Message{Partition: 0, Offset: 1}
+	m2 := kafka.Message{Partition: 0, Offset: 2}
+	m3 := kafka.Message{Partition: 0, Offset: 3}
+	other := kafka.Message{Partition: 1, Offset: 100}
+	for _, m := range []kafka.Message{m1, m2, m3, other} {
+		tracker.track(m)
+	}
+
+	// Третье обработано раньше первых двух — фиксировать нечего
+	_, ok := tracker.complete(m3)
+	assert.False(t, ok)
+
+	// Первое обработано — можно фиксировать только его
+	ready, ok := tracker.complete(m1)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), ready.Offset)
+
+	// Второе закрывает разрыв — можно фиксировать сразу третье
+	ready, ok = tracker.complete(m2)
+	require.True(t, ok)
+	assert.Equal(t, int64(3), ready.Offset)
+
+	// Другие партиции независимы
+	ready, ok = tracker.complete(other)
+	require.True(t, ok)
+	assert.Equal(t, int64(100), ready.Offset)
+}
+
+// TestWorkerIndex проверяет распределение сообщений по обработчикам
+func TestWorkerIndex(t *testing.T) {
+	a1 := orderMessage(t, 0, 1, "order-a")
+	a2 := orderMessage(t, 5, 2, "order-a")
+	assert.Equal(t, workerIndex(a1, KeyOrderUID, 8), workerIndex(a2, KeyOrderUID, 8),
+		"сообщения одного заказа должны попадать к одному обработчику")
+
+	assert.Equal(t, 1, workerIndex(a2, KeyPartition, 4), "в режиме partition важна только партиция")
+	assert.Equal(t, 2, workerIndex(kafka.Message{Partition: 6, Value: []byte("{bad")}, KeyOrderUID, 4),
+		"без order_uid сообщение распределяется по партиции")
+}
+
+// TestRunPool_ProcessesAndCommitsPerPartition проверяет параллельную обработку
+// и фиксацию последних смещений каждой партиции
+func TestRunPool_ProcessesAndCommitsPerPartition(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var msgs []kafka.Message
+	for p := 0; p < 3; p++ {
+		for off := int64(0); off < 10; off++ {
+			msgs = append(msgs, orderMessage(t, p, off, fmt.Sprintf("order-%d-%d", p, off)))
+		}
+	}
+	reader := &blockingReader{msgs: msgs, committed: make(map[int]int64)}
+
+	store := new(MockSaver)
+	store.On("SaveOrder", mock.Anything, mock.AnythingOfType("model.OrderData")).Return(nil)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runPool(ctx, reader, nil, store, Config{
+			CommitMode: CommitExplicit,
+			Workers:    4,
+			KeyBy:      KeyOrderUID,
+			Retry:      RetryPolicy{},
+		})
+	}()
+
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(map[int]int64{0: 9, 1: 9, 2: 9}, reader.committedOffsets())
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	store.AssertNumberOfCalls(t, "SaveOrder", 30)
+}
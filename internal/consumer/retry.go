@@ -0,0 +1,82 @@
+package consumer
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"time"
+)
+
+// defaultMaxRetryDelay ограничивает задержку, если MaxDelay не задан.
+const defaultMaxRetryDelay = 10 * time.Second
+
+// RetryPolicy описывает повторные попытки сохранения заказа при временных ошибках.
+type RetryPolicy struct {
+	MaxAttempts int           // общее число попыток, включая первую; <= 1 — без повторов
+	BaseDelay   time.Duration // задержка перед первым повтором
+	MaxDelay    time.Duration // верхняя граница задержки; <= 0 — defaultMaxRetryDelay
+	Jitter      float64       // доля случайного разброса задержки (0..1)
+
+	// IsRetryable решает, стоит ли повторять операцию после ошибки.
+	// Если не задана, ошибка считается постоянной.
+	IsRetryable func(error) bool
+}
+
+// Do выполняет fn, повторяя ее с экспоненциальной задержкой, пока ошибка временная
+// и не исчерпаны попытки. Возвращает последнюю ошибку.
+func (p RetryPolicy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	attempts := max(p.MaxAttempts, 1)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		if attempt >= attempts || p.IsRetryable == nil || !p.IsRetryable(err) {
+			return err
+		}
+
+		delay := p.delay(attempt)
+		log.Printf("Временная ошибка (попытка %d из %d), повтор через %v: %v", attempt, attempts, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// delay вычисляет задержку перед повтором номер attempt (начиная с 1):
+// BaseDelay * 2^(attempt-1) со случайным разбросом ±Jitter, но не больше MaxDelay.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxRetryDelay
+	}
+	d := p.BaseDelay
+	if d <= 0 {
+		return 0
+	}
+	for i := 1; i < attempt && d < maxDelay; i++ {
+		if d > maxDelay/2 { // удвоение превысит границу (и не переполнится)
+			d = maxDelay
+			break
+		}
+		d *= 2
+	}
+	d = min(d, maxDelay)
+
+	if p.Jitter > 0 {
+		jitter := min(p.Jitter, 1)
+		// Равномерно в диапазоне [d*(1-jitter), d*(1+jitter)], но не больше maxDelay
+		f := float64(d) * (1 - jitter + 2*jitter*rand.Float64())
+		if f >= float64(maxDelay) {
+			return maxDelay
+		}
+		d = time.Duration(f)
+	}
+	return d
+}
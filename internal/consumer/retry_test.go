@@ -0,0 +1,117 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTransient = errors.New("transient")
+
+// newTestRetryPolicy создает политику с минимальными задержками для тестов.
+func newTestRetryPolicy(maxAttempts int) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: maxAttempts,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    2 * time.Millisecond,
+		IsRetryable: func(err error) bool { return errors.Is(err, errTransient) },
+	}
+}
+
+// TestRetryPolicy_RetriesTransient проверяет повтор после временной ошибки
+func TestRetryPolicy_RetriesTransient(t *testing.T) {
+	calls := 0
+	err := newTestRetryPolicy(3).Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+// TestRetryPolicy_GivesUp проверяет, что после MaxAttempts возвращается последняя ошибка
+func TestRetryPolicy_GivesUp(t *testing.T) {
+	calls := 0
+	err := newTestRetryPolicy(3).Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 3, calls)
+}
+
+// TestRetryPolicy_PermanentError проверяет, что постоянная ошибка не повторяется
+func TestRetryPolicy_PermanentError(t *testing.T) {
+	permanent := errors.New("unique violation")
+	calls := 0
+	err := newTestRetryPolicy(5).Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return permanent
+	})
+
+	assert.ErrorIs(t, err, permanent)
+	assert.Equal(t, 1, calls)
+}
+
+// TestRetryPolicy_ContextCanceled проверяет прекращение повторов при отмене контекста
+func TestRetryPolicy_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := newTestRetryPolicy(10)
+	policy.BaseDelay = time.Hour
+	policy.MaxDelay = time.Hour
+
+	calls := 0
+	err := policy.Do(ctx, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return errTransient
+	})
+
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 1, calls)
+}
+
+// TestRetryPolicy_Delay проверяет экспоненциальный рост задержки и ее ограничение
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	assert.Equal(t, 100*time.Millisecond, policy.delay(1))
+	assert.Equal(t, 200*time.Millisecond, policy.delay(2))
+	assert.Equal(t, 400*time.Millisecond, policy.delay(3))
+	assert.Equal(t, time.Second, policy.delay(10))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := policy.delay(2)
+		assert.GreaterOrEqual(t, d, 100*time.Millisecond)
+		assert.LessOrEqual(t, d, 300*time.Millisecond)
+	}
+}
+
+// TestRetryPolicy_DelayBounded проверяет, что задержка ограничена и без MaxDelay
+// и не переполняется при большом числе попыток
+func TestRetryPolicy_DelayBounded(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond}
+	assert.Equal(t, defaultMaxRetryDelay, policy.delay(1000))
+
+	policy = RetryPolicy{BaseDelay: time.Hour, MaxDelay: math.MaxInt64}
+	assert.Equal(t, time.Duration(math.MaxInt64), policy.delay(1000))
+
+	// Разброс не выводит задержку за MaxDelay
+	policy = RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Second, Jitter: 1}
+	for range 100 {
+		assert.LessOrEqual(t, policy.delay(5), time.Second)
+	}
+
+	assert.Zero(t, RetryPolicy{}.delay(3))
+}
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+
+	"l1/internal/model"
+)
+
+// Колонки таблиц для массовой вставки через COPY.
+var (
+	deliveryCopyColumns = []string{"order_uid", "name", "phone", "zip", "city", "address", "region", "email", "country", "phone_e164"}
+	paymentCopyColumns  = []string{"order_uid", "transaction_id", "request_id", "currency", "provider", "amount", "payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee"}
+	ordersCopyColumns   = []string{"order_uid", "track_number", "entry", "customer_id", "delivery_service", "shardkey", "sm_id", "date_created", "oof_shard", "locale", "internal_signature", "payload_hash"}
+	itemsCopyColumns    = []string{"order_uid", "chrt_id", "track_number", "price", "rid", "name", "sale", "size", "total_price", "nm_id", "brand", "status"}
+)
+
+// SaveOrders сохраняет пачку заказов в одной транзакции. Новые заказы записываются
+// через COPY FROM (по одной операции на таблицу), уже существующие обрабатываются
+// так же, как в SaveOrder.
+//
+// Возвращает ошибки по каждому заказу (срез той же длины, что и orders) и общую ошибку,
+// если пачку не удалось сохранить целиком из-за временной проблемы с БД. Если пачка
+// не сохранилась из-за данных одного из заказов, заказы сохраняются по одному, чтобы
+// определить, какие именно из них ошибочны.
+func (p *PostgresStore) SaveOrders(ctx context.Context, orders []model.OrderData) ([]error, error) {
+	errs := make([]error, len(orders))
+	if len(orders) == 0 {
+		return errs, nil
+	}
+
+	err := p.saveOrdersBatch(ctx, orders, errs)
+	if err == nil {
+		return errs, nil
+	}
+	if ctx.Err() != nil || IsTransient(err) {
+		return nil, fmt.Errorf("не удалось сохранить пачку заказов: %w", err)
+	}
+
+	log.Printf("Пачка из %d заказов не сохранена (%v), сохраняем заказы по одному", len(orders), err)
+	for i, order := range orders {
+		if errs[i] != nil {
+			continue // уже отклонен при разборе пачки
+		}
+		errs[i] = p.SaveOrder(ctx, order)
+	}
+	return errs, nil
+}
+
+// saveOrdersBatch сохраняет пачку в одной транзакции. Ошибки отдельных заказов,
+// не мешающие сохранению остальных (конфликты), записываются в errs.
+func (p *PostgresStore) saveOrdersBatch(ctx context.Context, orders []model.OrderData, errs []error) (err error) {
+	hashes := make([]string, len(orders))
+	uids := make([]string, len(orders))
+	for i, order := range orders {
+		if hashes[i], err = payloadHash(order); err != nil {
+			return err
+		}
+		uids[i] = order.OrderUID
+	}
+
+	tx, err := p.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && rollbackErr != pgx.ErrTxClosed {
+				err = fmt.Errorf("ошибка при откате транзакции: %v (исходная ошибка: %w)", rollbackErr, err)
+			}
+		}
+	}()
+
+	// 1. Одним запросом узнаем, какие заказы уже сохранены, и блокируем их строки
+	existing, err := lockExistingHashes(ctx, tx, uids)
+	if err != nil {
+		return err
+	}
+
+	// 2. Разбираем пачку: новые заказы копятся для COPY, существующие обрабатываются сразу.
+	// known — хэш последней версии каждого заказа с учетом уже разобранной части пачки.
+	known := make(map[string]*string, len(orders))
+	for uid, hash := range existing {
+		known[uid] = hash
+	}
+	newIdx := make(map[string]int) // UID нового заказа -> индекс его последней версии
+	var newUIDs []string           // новые заказы в порядке появления
+
+	for i, order := range orders {
+		uid, hash := order.OrderUID, hashes[i]
+		prevHash, seen := known[uid]
+		if !seen {
+			newIdx[uid] = i
+			newUIDs = append(newUIDs, uid)
+			known[uid] = &hashes[i]
+			continue
+		}
+
+		skip, conflictErr := p.resolveExisting(uid, hash, prevHash)
+		if conflictErr != nil {
+			errs[i] = conflictErr
+			continue
+		}
+		if skip {
+			continue
+		}
+		known[uid] = &hashes[i]
+		if _, isNew := newIdx[uid]; isNew {
+			// Заказ встречается в пачке повторно и еще не записан — пишем последнюю версию
+			newIdx[uid] = i
+			continue
+		}
+		if err = writeOrder(ctx, tx, order, hash, true); err != nil {
+			return err
+		}
+	}
+
+	// 3. Новые заказы записываем через COPY
+	if len(newUIDs) > 0 {
+		batch := make([]model.OrderData, 0, len(newUIDs))
+		batchHashes := make([]string, 0, len(newUIDs))
+		for _, uid := range newUIDs {
+			batch = append(batch, orders[newIdx[uid]])
+			batchHashes = append(batchHashes, hashes[newIdx[uid]])
+		}
+		if err = copyOrders(ctx, tx, batch, batchHashes); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// lockExistingHashes возвращает хэши уже сохраненных заказов из uids, блокируя их строки.
+func lockExistingHashes(ctx context.Context, tx pgx.Tx, uids []string) (map[string]*string, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT order_uid, payload_hash FROM orders WHERE order_uid = ANY($1) FOR UPDATE`,
+		uids,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при проверке существования заказов: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]*string)
+	for rows.Next() {
+		var uid string
+		var hash *string
+		if err := rows.Scan(&uid, &hash); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении существующего заказа: %w", err)
+		}
+		existing[uid] = hash
+	}
+	return existing, rows.Err()
+}
+
+// copyOrders вставляет новые заказы через COPY FROM — по одной операции на таблицу.
+func copyOrders(ctx context.Context, tx pgx.Tx, orders []model.OrderData, hashes []string) error {
+	deliveries := make([][]any, 0, len(orders))
+	payments := make([][]any, 0, len(orders))
+	ordersRows := make([][]any, 0, len(orders))
+	var items [][]any
+	for i, o := range orders {
+		deliveries = append(deliveries, []any{
+			o.OrderUID, o.Delivery.Name, o.Delivery.Phone, o.Delivery.Zip,
+			o.Delivery.City, o.Delivery.Address, o.Delivery.Region, o.Delivery.Email,
+			deliveryCountry(&o), phoneE164(&o),
+		})
+		payments = append(payments, []any{
+			o.OrderUID, o.Payment.Transaction, o.Payment.RequestID, o.Payment.Currency, o.Payment.Provider,
+			o.Payment.Amount, o.Payment.PaymentDt, o.Payment.Bank, o.Payment.DeliveryCost,
+			o.Payment.GoodsTotal, o.Payment.CustomFee,
+		})
+		ordersRows = append(ordersRows, []any{
+			o.OrderUID, o.TrackNumber, o.Entry, o.CustomerID, o.DeliveryService,
+			o.Shardkey, o.SmID, o.DateCreated, o.OofShard, o.Locale, o.InternalSignature, hashes[i],
+		})
+		for _, item := range o.Items {
+			items = append(items, []any{
+				o.OrderUID, item.ChrtID, item.TrackNumber, item.Price, item.Rid, item.Name,
+				item.Sale, item.Size, item.TotalPrice, item.NmID, item.Brand, item.Status,
+			})
+		}
+	}
+
+	// Порядок важен: orders ссылается на delivery, payment и items — на orders
+	tables := []struct {
+		name    string
+		columns []string
+		rows    [][]any
+	}{
+		{"delivery", deliveryCopyColumns, deliveries},
+		{"orders", ordersCopyColumns, ordersRows},
+		{"payment", paymentCopyColumns, payments},
+		{"items", itemsCopyColumns, items},
+	}
+	for _, t := range tables {
+		if len(t.rows) == 0 {
+			continue
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{t.name}, t.columns, pgx.CopyFromRows(t.rows)); err != nil {
+			return fmt.Errorf("ошибка при массовой вставке в %s: %w", t.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"l1/internal/model"
+)
+
+// OrderEvent — событие о сохранении заказа.
+type OrderEvent struct {
+	Order   *model.OrderData
+	SavedAt time.Time
+}
+
+// Subscription — подписка на события о сохранении заказов.
+// События читаются из C; после Close канал закрывается.
+type Subscription struct {
+	C <-chan OrderEvent
+
+	ch      chan OrderEvent
+	b       *Broadcaster
+	once    sync.Once
+	dropped atomic.Uint64
+}
+
+// Close отменяет подписку. Повторные вызовы ничего не делают.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.b.mu.Lock()
+		delete(s.b.subs, s)
+		s.b.mu.Unlock()
+		close(s.ch)
+	})
+}
+
+// Dropped возвращает число событий, пропущенных из-за переполнения буфера подписки.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Broadcaster рассылает события всем подписчикам. Публикация не блокируется: если
+// подписчик не успевает читать и его буфер заполнен, событие для него пропускается.
+// Publish и Close можно вызывать у nil.
+type Broadcaster struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+// NewBroadcaster создает рассыльщик без подписчиков.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe создает подписку с буфером на buffer событий.
+func (b *Broadcaster) Subscribe(buffer int) *Subscription {
+	ch := make(chan OrderEvent, max(buffer, 1))
+	sub := &Subscription{C: ch, ch: ch, b: b}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// Publish отправляет событие всем подписчикам.
+func (b *Broadcaster) Publish(ev OrderEvent) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs {
+		select {
+		case sub.ch <- ev:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Close закрывает все подписки, чтобы читатели завершились.
+func (b *Broadcaster) Close() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	subs := make([]*Subscription, 0, len(b.subs))
+	for sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.Close()
+	}
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"l1/internal/model"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -10,46 +11,161 @@ import (
 type cacheEntry struct {
 	order     *model.OrderData
 	expiresAt time.Time
+	size      int64 // примерный размер заказа в байтах
+}
+
+// CacheOptions — настройки MemoryCache.
+type CacheOptions struct {
+	TTL time.Duration
+	// Ограничения размера кэша; если оба равны 0, кэш не ограничен и чистится только по TTL
+	MaxEntries int
+	MaxBytes   int64
+	Policy     EvictionPolicy // политика вытеснения, по умолчанию LRU
+	// Интервал фоновой очистки устаревших записей, по умолчанию 5 минут
+	CleanupInterval time.Duration
 }
 
 type MemoryCache struct {
-	cache  map[string]cacheEntry
-	mu     sync.RWMutex
-	ttl    time.Duration
-	once   sync.Once
-	stopCh chan struct{}
+	cache      map[string]cacheEntry
+	byTrack    map[string]string // вторичный индекс: track_number -> order_uid
+	mu         sync.RWMutex
+	ttl        time.Duration
+	maxEntries int
+	maxBytes   int64
+	bytes      int64   // суммарный размер записей
+	evictor    evictor // nil, если кэш не ограничен
+	stats      cacheCounters
+	once       sync.Once
+	stopCh     chan struct{}
+}
+
+// CacheStats — статистика работы кэша с момента создания.
+type CacheStats struct {
+	Hits        uint64 // найдено в кэше
+	Misses      uint64 // не найдено, включая просроченные записи
+	Expirations uint64 // удалено по TTL
+	Evictions   uint64 // вытеснено из-за ограничения размера
+	Rejections  uint64 // не принято в кэш политикой вытеснения или из-за размера
+	Sets        uint64 // записано в кэш
+	Deletes     uint64 // удалено при инвалидации
+	Size        int    // текущее число записей
+	Bytes       int64  // примерный текущий размер (считается только в ограниченном режиме)
+}
+
+// HitRatio возвращает долю обращений, обслуженных кэшем.
+func (s CacheStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// cacheCounters — счетчики статистики, обновляемые атомарно.
+type cacheCounters struct {
+	hits, misses, expirations, evictions, rejections, sets, deletes atomic.Uint64
 }
 
 // NewMemoryCache создает новый кэш с TTL и запускает фоновую очистку
 func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	return NewMemoryCacheWithOptions(CacheOptions{TTL: ttl})
+}
+
+// NewMemoryCacheWithOptions создает кэш с TTL и, при заданных лимитах, с вытеснением записей.
+func NewMemoryCacheWithOptions(opts CacheOptions) *MemoryCache {
 	mc := &MemoryCache{
-		cache:  make(map[string]cacheEntry),
-		ttl:    ttl,
-		stopCh: make(chan struct{}),
+		cache:      make(map[string]cacheEntry),
+		byTrack:    make(map[string]string),
+		ttl:        opts.TTL,
+		maxEntries: opts.MaxEntries,
+		maxBytes:   opts.MaxBytes,
+		stopCh:     make(chan struct{}),
+	}
+	if opts.MaxEntries > 0 || opts.MaxBytes > 0 {
+		mc.evictor = newEvictor(opts.Policy, opts.MaxEntries)
 	}
 
-	// Запускаем фоновую очистку каждые 5 минут
-	go mc.cleanupLoop(5 * time.Minute)
+	interval := opts.CleanupInterval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	go mc.cleanupLoop(interval)
 
 	return mc
 }
 
 // Get получает значение из кэша (thread-safe, lazy invalidation)
 func (m *MemoryCache) Get(uid string) (*model.OrderData, bool) {
+	if m.evictor != nil {
+		return m.getTracked(uid)
+	}
+
 	m.mu.RLock()
 	entry, ok := m.cache[uid]
 	m.mu.RUnlock()
 
 	if !ok {
+		m.stats.misses.Add(1)
 		return nil, false
 	}
 
 	// Проверяем TTL
 	if time.Now().After(entry.expiresAt) {
-		m.Delete(uid)
+		m.expire(uid)
+		m.stats.misses.Add(1)
 		return nil, false
 	}
 
+	m.stats.hits.Add(1)
+	return entry.order, true
+}
+
+// GetByTrackNumber ищет заказ в кэше по трек-номеру (thread-safe).
+// Если трек-номер есть у нескольких закэшированных заказов, возвращается самый новый
+// по date_created — тот же, что вернула бы БД.
+func (m *MemoryCache) GetByTrackNumber(track string) (*model.OrderData, bool) {
+	m.mu.RLock()
+	uid, ok := m.byTrack[track]
+	m.mu.RUnlock()
+
+	if !ok {
+		m.stats.misses.Add(1)
+		return nil, false
+	}
+	return m.Get(uid)
+}
+
+// expire удаляет запись, если она все еще просрочена.
+func (m *MemoryCache) expire(uid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Запись могла обновиться, пока блокировка была снята
+	if entry, ok := m.cache[uid]; ok && time.Now().After(entry.expiresAt) {
+		m.removeLocked(uid)
+		m.stats.expirations.Add(1)
+	}
+}
+
+// getTracked — Get для ограниченного кэша: обращение меняет порядок вытеснения,
+// поэтому выполняется под блокировкой на запись.
+func (m *MemoryCache) getTracked(uid string) (*model.OrderData, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.evictor.record(uid)
+	entry, ok := m.cache[uid]
+	if !ok {
+		m.stats.misses.Add(1)
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		m.removeLocked(uid)
+		m.stats.expirations.Add(1)
+		m.stats.misses.Add(1)
+		return nil, false
+	}
+	m.evictor.touch(uid)
+	m.stats.hits.Add(1)
 	return entry.order, true
 }
 
@@ -57,17 +173,134 @@ func (m *MemoryCache) Get(uid string) (*model.OrderData, bool) {
 func (m *MemoryCache) Set(uid string, order *model.OrderData) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.cache[uid] = cacheEntry{
+
+	entry := cacheEntry{
 		order:     order,
 		expiresAt: time.Now().Add(m.ttl),
 	}
+	if m.evictor == nil {
+		if old, ok := m.cache[uid]; ok {
+			m.unindexLocked(uid, old.order)
+		}
+		m.cache[uid] = entry
+		m.indexLocked(uid, order)
+		m.stats.sets.Add(1)
+		return
+	}
+
+	entry.size = orderSize(order)
+	old, exists := m.cache[uid]
+	if m.maxBytes > 0 && entry.size > m.maxBytes {
+		m.removeLocked(uid)       // прежняя версия устарела, а новая не помещается
+		m.stats.rejections.Add(1) // заказ больше всего кэша
+		return
+	}
+	m.evictor.record(uid)
+	if exists {
+		m.updateLocked(uid, old, entry)
+		return
+	}
+	if !m.makeRoomLocked(uid, entry.size, true) {
+		m.stats.rejections.Add(1)
+		return
+	}
+	m.cache[uid] = entry
+	m.indexLocked(uid, order)
+	m.bytes += entry.size
+	m.evictor.add(uid)
+	m.stats.sets.Add(1)
+}
+
+// updateLocked заменяет закэшированный заказ на месте: запись сохраняет накопленную
+// частоту обращений и не проходит проверку политики, а порядок вытеснения
+// обновляется как при обращении.
+func (m *MemoryCache) updateLocked(uid string, old, entry cacheEntry) {
+	m.evictor.touch(uid)
+	if !m.makeRoomLocked(uid, entry.size-old.size, false) {
+		m.removeLocked(uid) // прежняя версия устарела, а новая не помещается
+		m.stats.rejections.Add(1)
+		return
+	}
+	m.unindexLocked(uid, old.order)
+	m.cache[uid] = entry
+	m.indexLocked(uid, entry.order)
+	m.bytes += entry.size - old.size
+	m.stats.sets.Add(1)
+}
+
+// makeRoomLocked вытесняет записи, пока запись uid не поместится в лимиты: size —
+// сколько байт она добавляет, isNew — добавляется ли новая запись (тогда она
+// проходит проверку политики и занимает место в лимите числа записей).
+// Возвращает false, если политика отказалась принимать новую запись или место
+// можно освободить, только вытеснив саму запись uid.
+func (m *MemoryCache) makeRoomLocked(uid string, size int64, isNew bool) bool {
+	for (isNew && m.maxEntries > 0 && len(m.cache) >= m.maxEntries) || (m.maxBytes > 0 && m.bytes+size > m.maxBytes) {
+		victim, ok := m.evictor.victim()
+		if !ok || victim == uid {
+			return false
+		}
+		if isNew && !m.evictor.admit(uid, victim) {
+			return false
+		}
+		m.removeLocked(victim)
+		m.stats.evictions.Add(1)
+	}
+	return true
+}
+
+// removeLocked удаляет запись; вызывается под блокировкой на запись.
+// Возвращает false, если записи не было.
+func (m *MemoryCache) removeLocked(uid string) bool {
+	entry, ok := m.cache[uid]
+	if !ok {
+		return false
+	}
+	delete(m.cache, uid)
+	m.unindexLocked(uid, entry.order)
+	m.bytes -= entry.size
+	if m.evictor != nil {
+		m.evictor.remove(uid)
+	}
+	return true
+}
+
+// indexLocked добавляет заказ во вторичные индексы; вызывается под блокировкой на запись.
+// Трек-номер указывает на самый новый заказ по date_created (при равенстве — с большим
+// UID), как в GetOrderByTrackNumber, а не на закэшированный последним.
+func (m *MemoryCache) indexLocked(uid string, order *model.OrderData) {
+	if order == nil || order.TrackNumber == "" {
+		return
+	}
+	if cur, ok := m.byTrack[order.TrackNumber]; ok && cur != uid {
+		if indexed, ok := m.cache[cur]; ok && !newerOrder(order, uid, indexed.order, cur) {
+			return
+		}
+	}
+	m.byTrack[order.TrackNumber] = uid
+}
+
+// newerOrder сообщает, идет ли заказ a раньше b при сортировке по date_created DESC, order_uid DESC.
+func newerOrder(a *model.OrderData, aUID string, b *model.OrderData, bUID string) bool {
+	if !a.DateCreated.Equal(b.DateCreated) {
+		return a.DateCreated.After(b.DateCreated)
+	}
+	return aUID > bUID
+}
+
+// unindexLocked убирает заказ из вторичных индексов, если они указывают на него.
+func (m *MemoryCache) unindexLocked(uid string, order *model.OrderData) {
+	if order != nil && m.byTrack[order.TrackNumber] == uid {
+		delete(m.byTrack, order.TrackNumber)
+	}
 }
 
 // Delete реализует инвалидацию кэша (thread-safe)
 func (m *MemoryCache) Delete(uid string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	delete(m.cache, uid)
+	if m.removeLocked(uid) {
+		m.stats.deletes.Add(1)
+	}
 }
 
 // Count возвращает количество элементов в кэше (thread-safe)
@@ -77,6 +310,25 @@ func (m *MemoryCache) Count() int {
 	return len(m.cache)
 }
 
+// Stats возвращает статистику кэша (thread-safe)
+func (m *MemoryCache) Stats() CacheStats {
+	m.mu.RLock()
+	size, bytes := len(m.cache), m.bytes
+	m.mu.RUnlock()
+
+	return CacheStats{
+		Hits:        m.stats.hits.Load(),
+		Misses:      m.stats.misses.Load(),
+		Expirations: m.stats.expirations.Load(),
+		Evictions:   m.stats.evictions.Load(),
+		Rejections:  m.stats.rejections.Load(),
+		Sets:        m.stats.sets.Load(),
+		Deletes:     m.stats.deletes.Load(),
+		Size:        size,
+		Bytes:       bytes,
+	}
+}
+
 // cleanupLoop — фоновая очистка устаревших записей
 func (m *MemoryCache) cleanupLoop(interval time.Duration) {
 	ticker := time.NewTicker(interval)
@@ -99,7 +351,11 @@ func (m *MemoryCache) cleanupLoop(interval time.Duration) {
 			if len(expiredKeys) > 0 {
 				m.mu.Lock()
 				for _, uid := range expiredKeys {
-					delete(m.cache, uid)
+					// Запись могла обновиться, пока блокировка была снята
+					if entry, ok := m.cache[uid]; ok && time.Now().After(entry.expiresAt) {
+						m.removeLocked(uid)
+						m.stats.expirations.Add(1)
+					}
 				}
 				m.mu.Unlock()
 			}
@@ -115,3 +371,21 @@ func (m *MemoryCache) Close() {
 		close(m.stopCh)
 	})
 }
+
+// orderSize примерно оценивает, сколько памяти занимает заказ.
+func orderSize(o *model.OrderData) int64 {
+	const (
+		orderOverhead = 512 // структуры заказа, доставки и оплаты
+		itemOverhead  = 160
+	)
+	size := orderOverhead + len(o.OrderUID) + len(o.TrackNumber) + len(o.Entry) + len(o.Locale) +
+		len(o.InternalSignature) + len(o.CustomerID) + len(o.DeliveryService) + len(o.Shardkey) + len(o.OofShard) +
+		len(o.Delivery.Name) + len(o.Delivery.Phone) + len(o.Delivery.Zip) + len(o.Delivery.City) +
+		len(o.Delivery.Address) + len(o.Delivery.Region) + len(o.Delivery.Email) +
+		len(o.Payment.Transaction) + len(o.Payment.RequestID) + len(o.Payment.Currency) +
+		len(o.Payment.Provider) + len(o.Payment.Bank)
+	for _, item := range o.Items {
+		size += itemOverhead + len(item.TrackNumber) + len(item.Rid) + len(item.Name) + len(item.Size) + len(item.Brand)
+	}
+	return int64(size)
+}
@@ -43,6 +43,72 @@ func TestMemoryCache_GetNotFound(t *testing.T) {
 	assert.Nil(t, retrieved, "Получен заказ вместо nil для несуществующего ключа")
 }
 
+// TestMemoryCache_GetByTrackNumber — тест вторичного индекса по трек-номеру.
+func TestMemoryCache_GetByTrackNumber(t *testing.T) {
+	cache := NewMemoryCache(5 * time.Minute)
+	defer cache.Close()
+
+	cache.Set("order-1", &model.OrderData{OrderUID: "order-1", TrackNumber: "TRACK-1"})
+
+	// 1. Заказ находится по трек-номеру
+	retrieved, ok := cache.GetByTrackNumber("TRACK-1")
+	require.True(t, ok)
+	assert.Equal(t, "order-1", retrieved.OrderUID)
+
+	// 2. После смены трек-номера старый больше не находит заказ
+	cache.Set("order-1", &model.OrderData{OrderUID: "order-1", TrackNumber: "TRACK-2"})
+	_, ok = cache.GetByTrackNumber("TRACK-1")
+	assert.False(t, ok, "индекс не должен указывать на устаревший трек-номер")
+	_, ok = cache.GetByTrackNumber("TRACK-2")
+	assert.True(t, ok)
+
+	// 3. Инвалидация убирает заказ из индекса
+	cache.Delete("order-1")
+	_, ok = cache.GetByTrackNumber("TRACK-2")
+	assert.False(t, ok)
+}
+
+// TestMemoryCache_GetByTrackNumber_Evicted — тест очистки индекса при вытеснении.
+func TestMemoryCache_GetByTrackNumber_Evicted(t *testing.T) {
+	cache := NewMemoryCacheWithOptions(CacheOptions{TTL: time.Minute, MaxEntries: 1})
+	defer cache.Close()
+
+	cache.Set("order-1", &model.OrderData{OrderUID: "order-1", TrackNumber: "TRACK-1"})
+	cache.Set("order-2", &model.OrderData{OrderUID: "order-2", TrackNumber: "TRACK-2"})
+
+	_, ok := cache.GetByTrackNumber("TRACK-1")
+	assert.False(t, ok, "вытесненный заказ не должен находиться по трек-номеру")
+	retrieved, ok := cache.GetByTrackNumber("TRACK-2")
+	require.True(t, ok)
+	assert.Equal(t, "order-2", retrieved.OrderUID)
+}
+
+// TestMemoryCache_GetByTrackNumber_Newest — при общем трек-номере находится самый новый
+// заказ, как в БД, независимо от порядка записи в кэш.
+func TestMemoryCache_GetByTrackNumber_Newest(t *testing.T) {
+	// --- Arrange ---
+	cache := NewMemoryCacheWithOptions(CacheOptions{TTL: time.Minute, MaxEntries: 2})
+	defer cache.Close()
+	created := time.Date(2025, 10, 26, 8, 0, 0, 0, time.UTC)
+	newer := &model.OrderData{OrderUID: "order-new", TrackNumber: "TRACK", DateCreated: created.Add(time.Hour)}
+	older := &model.OrderData{OrderUID: "order-old", TrackNumber: "TRACK", DateCreated: created}
+
+	// --- Act ---
+	cache.Set(newer.OrderUID, newer)
+	cache.Set(older.OrderUID, older) // например, повторная доставка старого заказа
+
+	// --- Assert ---
+	got, ok := cache.GetByTrackNumber("TRACK")
+	require.True(t, ok)
+	assert.Equal(t, "order-new", got.OrderUID)
+
+	// Вытесненный заказ больше не находится по трек-номеру
+	cache.Set("order-3", &model.OrderData{OrderUID: "order-3", TrackNumber: "OTHER"})
+	cache.Set("order-4", &model.OrderData{OrderUID: "order-4", TrackNumber: "OTHER-2"})
+	_, ok = cache.GetByTrackNumber("TRACK")
+	assert.False(t, ok)
+}
+
 // TestMemoryCache_LazyTTLExpiration — тест "ленивой" инвалидации при Get.
 func TestMemoryCache_LazyTTLExpiration(t *testing.T) {
 	// Используем очень маленький TTL для теста
@@ -184,3 +250,227 @@ func TestMemoryCache_Concurrency(t *testing.T) {
 	// но можем проверить, что он не паникует.
 	t.Logf("Финальное количество в кэше после теста на конкурентность: %d", cache.Count())
 }
+
+// newBoundedCache создает ограниченный кэш для тестов.
+func newBoundedCache(t *testing.T, opts CacheOptions) *MemoryCache {
+	t.Helper()
+	if opts.TTL == 0 {
+		opts.TTL = time.Minute
+	}
+	cache := NewMemoryCacheWithOptions(opts)
+	t.Cleanup(cache.Close)
+	return cache
+}
+
+// TestMemoryCache_MaxEntriesLRU — вытесняется заказ, к которому дольше всего не обращались.
+func TestMemoryCache_MaxEntriesLRU(t *testing.T) {
+	cache := newBoundedCache(t, CacheOptions{MaxEntries: 2, Policy: EvictLRU})
+
+	cache.Set("order-1", newTestOrder("order-1"))
+	cache.Set("order-2", newTestOrder("order-2"))
+	_, ok := cache.Get("order-1") // order-1 становится самым свежим
+	require.True(t, ok)
+	cache.Set("order-3", newTestOrder("order-3"))
+
+	assert.Equal(t, 2, cache.Count(), "Кэш не должен превышать лимит")
+	_, ok = cache.Get("order-2")
+	assert.False(t, ok, "order-2 должен быть вытеснен")
+	_, ok = cache.Get("order-1")
+	assert.True(t, ok)
+}
+
+// TestMemoryCache_MaxEntriesLFU — вытесняется заказ с наименьшим числом обращений.
+func TestMemoryCache_MaxEntriesLFU(t *testing.T) {
+	cache := newBoundedCache(t, CacheOptions{MaxEntries: 2, Policy: EvictLFU})
+
+	cache.Set("order-1", newTestOrder("order-1"))
+	cache.Set("order-2", newTestOrder("order-2"))
+	for range 3 {
+		cache.Get("order-1")
+	}
+	cache.Get("order-2")
+	cache.Set("order-3", newTestOrder("order-3"))
+	cache.Set("order-4", newTestOrder("order-4"))
+
+	_, ok := cache.Get("order-1")
+	assert.True(t, ok, "Часто запрашиваемый заказ должен остаться в кэше")
+	_, ok = cache.Get("order-4")
+	assert.True(t, ok)
+	assert.Equal(t, 2, cache.Count())
+}
+
+// TestMemoryCache_TinyLFUAdmission — редкий заказ не вытесняет популярный.
+func TestMemoryCache_TinyLFUAdmission(t *testing.T) {
+	cache := newBoundedCache(t, CacheOptions{MaxEntries: 1, Policy: EvictTinyLFU})
+
+	cache.Set("popular", newTestOrder("popular"))
+	for range 3 {
+		cache.Get("popular")
+	}
+
+	// Заказ, который ни разу не запрашивали, в кэш не попадает
+	cache.Set("rare", newTestOrder("rare"))
+	_, ok := cache.Get("popular")
+	assert.True(t, ok, "Популярный заказ не должен быть вытеснен")
+
+	// Заказ, который запрашивают чаще, вытесняет популярный
+	for range 10 {
+		cache.Get("rising")
+	}
+	cache.Set("rising", newTestOrder("rising"))
+	_, ok = cache.Get("rising")
+	assert.True(t, ok, "Часто запрашиваемый заказ должен попасть в кэш")
+	assert.Equal(t, 1, cache.Count())
+}
+
+// TestMemoryCache_TinyLFUAdmitsNewWrites — новый заказ попадает в заполненный кэш
+// без предварительного Get, вытесняя заказ, который только записали.
+func TestMemoryCache_TinyLFUAdmitsNewWrites(t *testing.T) {
+	// --- Arrange ---
+	cache := newBoundedCache(t, CacheOptions{MaxEntries: 2, Policy: EvictTinyLFU})
+	cache.Set("order-1", newTestOrder("order-1"))
+	cache.Set("order-2", newTestOrder("order-2"))
+
+	// --- Act ---
+	cache.Set("order-3", newTestOrder("order-3"))
+
+	// --- Assert ---
+	_, ok := cache.Get("order-3")
+	assert.True(t, ok, "Свежий заказ должен попасть в кэш")
+	_, ok = cache.Get("order-1")
+	assert.False(t, ok, "Вытесняется самый давний заказ")
+	assert.Equal(t, 2, cache.Count())
+	assert.Zero(t, cache.Stats().Rejections)
+}
+
+// TestMemoryCache_UpdateKeepsFrequency — повторное сохранение популярного заказа
+// не сбрасывает его частоту: он не становится первым кандидатом на вытеснение.
+func TestMemoryCache_UpdateKeepsFrequency(t *testing.T) {
+	for _, policy := range []EvictionPolicy{EvictLFU, EvictTinyLFU} {
+		t.Run(string(policy), func(t *testing.T) {
+			// --- Arrange ---
+			cache := newBoundedCache(t, CacheOptions{MaxEntries: 2, Policy: policy})
+			cache.Set("hot", newTestOrder("hot"))
+			for range 5 {
+				cache.Get("hot")
+			}
+			cache.Set("cold", newTestOrder("cold"))
+			cache.Get("cold")
+
+			// --- Act ---
+			updated := newTestOrder("hot")
+			updated.TrackNumber = "NEW"
+			cache.Set("hot", updated)
+			cache.Set("new", newTestOrder("new"))
+			cache.Get("new")
+			cache.Set("new", newTestOrder("new"))
+
+			// --- Assert ---
+			got, ok := cache.Get("hot")
+			require.True(t, ok, "Популярный заказ не должен вытесняться после обновления")
+			assert.Equal(t, "NEW", got.TrackNumber)
+			_, ok = cache.Get("cold")
+			assert.False(t, ok)
+		})
+	}
+}
+
+// TestMemoryCache_MaxBytes — кэш укладывается в бюджет памяти.
+func TestMemoryCache_MaxBytes(t *testing.T) {
+	size := orderSize(newTestOrder("order-1"))
+	cache := newBoundedCache(t, CacheOptions{MaxBytes: 2 * size})
+
+	for i := range 5 {
+		uid := fmt.Sprintf("order-%d", i)
+		cache.Set(uid, newTestOrder(uid))
+	}
+
+	assert.Equal(t, 2, cache.Count())
+	cache.mu.RLock()
+	assert.LessOrEqual(t, cache.bytes, 2*size)
+	cache.mu.RUnlock()
+
+	// Заказ больше всего бюджета не кэшируется
+	huge := newTestOrder("huge")
+	huge.Items = make([]model.Item, 100)
+	cache.Set("huge", huge)
+	_, ok := cache.Get("huge")
+	assert.False(t, ok)
+}
+
+// TestMemoryCache_BoundedUpdateAndDelete — обновление и удаление корректно учитывают размер.
+func TestMemoryCache_BoundedUpdateAndDelete(t *testing.T) {
+	cache := newBoundedCache(t, CacheOptions{MaxEntries: 2, Policy: EvictTinyLFU})
+
+	cache.Set("order-1", newTestOrder("order-1"))
+	updated := newTestOrder("order-1")
+	updated.TrackNumber = "NEW"
+	cache.Set("order-1", updated)
+
+	got, ok := cache.Get("order-1")
+	require.True(t, ok, "Обновление заказа не должно отклоняться политикой")
+	assert.Equal(t, "NEW", got.TrackNumber)
+	assert.Equal(t, 1, cache.Count())
+
+	cache.Delete("order-1")
+	cache.mu.RLock()
+	assert.Zero(t, cache.bytes)
+	cache.mu.RUnlock()
+}
+
+// TestMemoryCache_BoundedTTL — в ограниченном режиме TTL продолжает работать.
+func TestMemoryCache_BoundedTTL(t *testing.T) {
+	ttl := 30 * time.Millisecond
+	cache := newBoundedCache(t, CacheOptions{TTL: ttl, MaxEntries: 10, CleanupInterval: 10 * time.Millisecond})
+
+	cache.Set("order-1", newTestOrder("order-1"))
+	time.Sleep(ttl + 30*time.Millisecond)
+
+	assert.Equal(t, 0, cache.Count(), "Фоновая очистка должна удалять просроченные записи")
+	_, ok := cache.Get("order-1")
+	assert.False(t, ok)
+}
+
+// TestMemoryCache_Stats — статистика учитывает попадания, промахи, TTL, вытеснение и удаление.
+func TestMemoryCache_Stats(t *testing.T) {
+	ttl := 30 * time.Millisecond
+	cache := newBoundedCache(t, CacheOptions{TTL: ttl, MaxEntries: 2})
+
+	cache.Set("order-1", newTestOrder("order-1"))
+	cache.Set("order-2", newTestOrder("order-2"))
+	cache.Get("order-1")                          // попадание
+	cache.Get("missing")                          // промах
+	cache.Set("order-3", newTestOrder("order-3")) // вытесняет order-2
+	cache.Delete("order-3")
+	cache.Delete("order-3") // повторное удаление не считается
+	time.Sleep(ttl + 10*time.Millisecond)
+	cache.Get("order-1") // просрочен: промах и истечение
+
+	stats := cache.Stats()
+	assert.Equal(t, CacheStats{
+		Hits:        1,
+		Misses:      2,
+		Expirations: 1,
+		Evictions:   1,
+		Sets:        3,
+		Deletes:     1,
+	}, stats)
+	assert.InDelta(t, 1.0/3, stats.HitRatio(), 1e-9)
+}
+
+// TestMemoryCache_StatsUnbounded — статистика в неограниченном режиме.
+func TestMemoryCache_StatsUnbounded(t *testing.T) {
+	cache := NewMemoryCache(time.Minute)
+	defer cache.Close()
+
+	cache.Set("order-1", newTestOrder("order-1"))
+	cache.Get("order-1")
+	cache.Get("order-2")
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Sets)
+	assert.Equal(t, 1, stats.Size)
+	assert.Zero(t, CacheStats{}.HitRatio())
+}
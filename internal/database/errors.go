@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// ErrOrderConflict возвращается, когда заказ с таким UID уже сохранен с другими данными,
+	// а перезапись запрещена настройками хранилища.
+	ErrOrderConflict = errors.New("конфликт данных заказа")
+	// ErrNotFound — заказа с таким UID нет.
+	ErrNotFound = errors.New("заказ не найден")
+	// ErrUnavailable — хранилище временно недоступно, запрос можно повторить позже.
+	ErrUnavailable = errors.New("хранилище заказов недоступно")
+	// ErrInvalidUID — UID заказа пустой или имеет недопустимый формат.
+	ErrInvalidUID = errors.New("некорректный UID заказа")
+	// ErrInvalidQuery — некорректные параметры поиска заказов (сортировка, курсор).
+	ErrInvalidQuery = errors.New("некорректный запрос списка заказов")
+)
+
+// kindError добавляет к ошибке категорию (один из сентинелов пакета), не меняя ее текст.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string { return e.err.Error() }
+
+func (e *kindError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// withKind помечает err категорией kind, чтобы ее можно было проверить через errors.Is.
+func withKind(kind, err error) error {
+	return &kindError{kind: kind, err: err}
+}
+
+// classifyReadError помечает ошибку чтения из БД: временные сбои — ErrUnavailable.
+// Отмена и истечение контекста остаются как есть, чтобы вызывающая сторона отличала их.
+func classifyReadError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if IsTransient(err) {
+		return withKind(ErrUnavailable, err)
+	}
+	return err
+}
+
+// maxUIDLength — ограничение длины order_uid, track_number и customer_id в схеме БД.
+const maxUIDLength = 255
+
+// ValidateUID проверяет UID заказа до обращения к кэшу и БД.
+func ValidateUID(uid string) error {
+	return validateKey("UID заказа", uid, ErrInvalidUID)
+}
+
+// ValidateTrackNumber проверяет трек-номер заказа.
+func ValidateTrackNumber(track string) error {
+	return validateKey("трек-номер", track, ErrInvalidQuery)
+}
+
+// ValidateCustomerID проверяет идентификатор покупателя.
+func ValidateCustomerID(customerID string) error {
+	return validateKey("идентификатор покупателя", customerID, ErrInvalidQuery)
+}
+
+// validateKey проверяет идентификатор, который приходит в пути запроса:
+// непустой, не длиннее колонки в БД, без пробелов, управляющих символов и '/'.
+func validateKey(name, value string, kind error) error {
+	if value == "" {
+		return withKind(kind, fmt.Errorf("%s не указан", name))
+	}
+	if len(value) > maxUIDLength {
+		return withKind(kind, fmt.Errorf("%s длиннее %d символов", name, maxUIDLength))
+	}
+	for _, r := range value {
+		if r == '/' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return withKind(kind, fmt.Errorf("%s содержит недопустимый символ %q", name, r))
+		}
+	}
+	return nil
+}
+
+// Коды ошибок PostgreSQL (SQLSTATE), которые используются при классификации ошибок.
+const (
+	pgCodeSerializationFailure = "40001"
+	pgCodeDeadlockDetected     = "40P01"
+	pgCodeTooManyConnections   = "53300"
+	pgCodeAdminShutdown        = "57P01"
+	pgCodeCrashShutdown        = "57P02"
+	pgCodeCannotConnectNow     = "57P03"
+)
+
+// IsTransient сообщает, является ли ошибка временной, т.е. имеет ли смысл повторить операцию:
+// недоступность сервера, обрыв соединения, конфликт сериализации или дедлок.
+// Ошибки данных (нарушение уникальности, ограничений и т.п.) считаются постоянными.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	// Отмена операции вызывающей стороной — не повод для повтора
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgCodeSerializationFailure, pgCodeDeadlockDetected, pgCodeTooManyConnections,
+			pgCodeAdminShutdown, pgCodeCrashShutdown, pgCodeCannotConnectNow:
+			return true
+		}
+		// Класс 08 — ошибки соединения
+		return strings.HasPrefix(pgErr.Code, "08")
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+	if pgconn.SafeToRetry(err) || pgconn.Timeout(err) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIsTransient проверяет классификацию ошибок на временные и постоянные
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, want: true},
+		{name: "deadlock", err: &pgconn.PgError{Code: "40P01"}, want: true},
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}, want: true},
+		{name: "admin shutdown", err: &pgconn.PgError{Code: "57P01"}, want: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, want: false},
+		{name: "not null violation", err: &pgconn.PgError{Code: "23502"}, want: false},
+		{name: "connection refused", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED), want: true},
+		{name: "wrapped deadlock", err: fmt.Errorf("ошибка при сохранении: %w", &pgconn.PgError{Code: "40P01"}), want: true},
+		{name: "context canceled", err: context.Canceled, want: false},
+		{name: "generic", err: errors.New("something"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsTransient(tt.err))
+		})
+	}
+}
+
+// TestValidateUID проверяет проверку формата UID заказа
+func TestValidateUID(t *testing.T) {
+	tests := []struct {
+		name  string
+		uid   string
+		valid bool
+	}{
+		{name: "обычный", uid: "b563feb7b2b84b6test", valid: true},
+		{name: "пустой", uid: "", valid: false},
+		{name: "пробел", uid: "abc def", valid: false},
+		{name: "слэш", uid: "abc/def", valid: false},
+		{name: "управляющий символ", uid: "abc\x00", valid: false},
+		{name: "слишком длинный", uid: strings.Repeat("a", maxUIDLength+1), valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUID(tt.uid)
+			if tt.valid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidUID)
+		})
+	}
+}
+
+// TestWithKind проверяет, что категория ошибки не теряет исходную причину и текст
+func TestWithKind(t *testing.T) {
+	cause := &pgconn.PgError{Code: "08006", Message: "connection failure"}
+	err := fmt.Errorf("запрос: %w", withKind(ErrUnavailable, cause))
+
+	assert.ErrorIs(t, err, ErrUnavailable)
+	var pgErr *pgconn.PgError
+	assert.ErrorAs(t, err, &pgErr)
+	assert.Equal(t, "запрос: "+cause.Error(), err.Error())
+}
@@ -0,0 +1,256 @@
+package database
+
+import (
+	"container/list"
+	"hash/maphash"
+)
+
+// EvictionPolicy определяет, какие записи вытесняются из ограниченного кэша.
+type EvictionPolicy string
+
+const (
+	// EvictLRU — вытесняется запись, к которой дольше всего не обращались.
+	EvictLRU EvictionPolicy = "lru"
+	// EvictLFU — вытесняется запись с наименьшим числом обращений.
+	EvictLFU EvictionPolicy = "lfu"
+	// EvictTinyLFU — вытеснение как в LRU, но новая запись попадает в кэш, только если
+	// по оценке частоты обращений к ней обращаются не реже, чем к вытесняемой.
+	EvictTinyLFU EvictionPolicy = "tinylfu"
+)
+
+// evictor — порядок вытеснения записей кэша. Методы вызываются под блокировкой кэша.
+type evictor interface {
+	// record учитывает обращение к ключу, в том числе к отсутствующему в кэше.
+	record(uid string)
+	// add добавляет новую запись.
+	add(uid string)
+	// touch отмечает обращение к записи, которая есть в кэше.
+	touch(uid string)
+	// remove удаляет запись.
+	remove(uid string)
+	// victim возвращает запись, которую следует вытеснить первой.
+	victim() (string, bool)
+	// admit решает, стоит ли вытеснять victim ради новой записи candidate.
+	admit(candidate, victim string) bool
+}
+
+// newEvictor создает порядок вытеснения для политики. capacity — ожидаемое число
+// записей в кэше, по нему подбирается размер счетчика частот TinyLFU.
+func newEvictor(policy EvictionPolicy, capacity int) evictor {
+	switch policy {
+	case EvictLFU:
+		return newLFU()
+	case EvictTinyLFU:
+		return &tinyLFU{lru: newLRU(), sketch: newFrequencySketch(capacity)}
+	default:
+		return newLRU()
+	}
+}
+
+// --- LRU ---
+
+type lru struct {
+	order *list.List // в начале — самые недавно использованные
+	items map[string]*list.Element
+}
+
+func newLRU() *lru {
+	return &lru{order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (l *lru) record(string) {}
+
+func (l *lru) add(uid string) {
+	l.items[uid] = l.order.PushFront(uid)
+}
+
+func (l *lru) touch(uid string) {
+	if el, ok := l.items[uid]; ok {
+		l.order.MoveToFront(el)
+	}
+}
+
+func (l *lru) remove(uid string) {
+	if el, ok := l.items[uid]; ok {
+		l.order.Remove(el)
+		delete(l.items, uid)
+	}
+}
+
+func (l *lru) victim() (string, bool) {
+	el := l.order.Back()
+	if el == nil {
+		return "", false
+	}
+	return el.Value.(string), true
+}
+
+func (l *lru) admit(string, string) bool { return true }
+
+// --- LFU ---
+
+// lfu хранит записи списками по числу обращений. Среди записей с одинаковой
+// частотой вытесняется та, к которой дольше всего не обращались.
+type lfu struct {
+	items   map[string]*list.Element
+	buckets map[int]*list.List // частота -> записи, в начале — самые недавние
+	minFreq int
+}
+
+type lfuEntry struct {
+	uid  string
+	freq int
+}
+
+func newLFU() *lfu {
+	return &lfu{items: make(map[string]*list.Element), buckets: make(map[int]*list.List)}
+}
+
+func (l *lfu) record(string) {}
+
+func (l *lfu) push(uid string, freq int) {
+	bucket, ok := l.buckets[freq]
+	if !ok {
+		bucket = list.New()
+		l.buckets[freq] = bucket
+	}
+	l.items[uid] = bucket.PushFront(&lfuEntry{uid: uid, freq: freq})
+}
+
+// unlink удаляет элемент из списка его частоты и возвращает эту частоту.
+func (l *lfu) unlink(el *list.Element) int {
+	freq := el.Value.(*lfuEntry).freq
+	bucket := l.buckets[freq]
+	bucket.Remove(el)
+	if bucket.Len() == 0 {
+		delete(l.buckets, freq)
+	}
+	return freq
+}
+
+func (l *lfu) add(uid string) {
+	l.push(uid, 1)
+	l.minFreq = 1
+}
+
+func (l *lfu) touch(uid string) {
+	el, ok := l.items[uid]
+	if !ok {
+		return
+	}
+	freq := l.unlink(el)
+	if freq == l.minFreq && l.buckets[freq] == nil {
+		l.minFreq = freq + 1
+	}
+	l.push(uid, freq+1)
+}
+
+func (l *lfu) remove(uid string) {
+	el, ok := l.items[uid]
+	if !ok {
+		return
+	}
+	l.unlink(el)
+	delete(l.items, uid)
+	// minFreq пересчитывается лениво в victim
+}
+
+func (l *lfu) victim() (string, bool) {
+	if len(l.items) == 0 {
+		return "", false
+	}
+	for l.buckets[l.minFreq] == nil {
+		l.minFreq++
+	}
+	return l.buckets[l.minFreq].Back().Value.(*lfuEntry).uid, true
+}
+
+func (l *lfu) admit(string, string) bool { return true }
+
+// --- TinyLFU ---
+
+// tinyLFU вытесняет записи в порядке LRU, но пропускает в кэш новую запись, только
+// если по счетчику частот к ней обращаются не реже, чем к вытесняемой. Запись
+// в кэш тоже считается обращением, поэтому новый заказ вытесняет заказ, который
+// только записали и ни разу не читали, а разовые обращения (например, перебор
+// заказов) не вымывают из кэша популярные заказы.
+type tinyLFU struct {
+	*lru
+	sketch *frequencySketch
+}
+
+func (t *tinyLFU) record(uid string) {
+	t.sketch.increment(uid)
+}
+
+func (t *tinyLFU) admit(candidate, victim string) bool {
+	return t.sketch.estimate(candidate) >= t.sketch.estimate(victim)
+}
+
+// frequencySketch — count-min sketch с 4-битными счетчиками и периодическим
+// старением: когда число учтенных обращений достигает resetAt, все счетчики
+// делятся пополам, чтобы старая популярность постепенно забывалась.
+type frequencySketch struct {
+	seed     maphash.Seed
+	counters [sketchDepth][]uint8
+	mask     uint64
+	added    int
+	resetAt  int
+}
+
+const (
+	sketchDepth      = 4
+	sketchMaxCounter = 15
+)
+
+func newFrequencySketch(capacity int) *frequencySketch {
+	width := 64
+	for width < capacity {
+		width <<= 1
+	}
+	s := &frequencySketch{seed: maphash.MakeSeed(), mask: uint64(width - 1), resetAt: 10 * width}
+	for i := range s.counters {
+		s.counters[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// indexes возвращает позиции ключа в каждой строке счетчиков.
+func (s *frequencySketch) indexes(uid string) [sketchDepth]uint64 {
+	h := maphash.String(s.seed, uid)
+	h1, h2 := h, h>>32|h<<32
+	var idx [sketchDepth]uint64
+	for i := range idx {
+		idx[i] = (h1 + uint64(i)*h2) & s.mask
+	}
+	return idx
+}
+
+func (s *frequencySketch) increment(uid string) {
+	for i, j := range s.indexes(uid) {
+		if s.counters[i][j] < sketchMaxCounter {
+			s.counters[i][j]++
+		}
+	}
+	s.added++
+	if s.added >= s.resetAt {
+		s.reset()
+	}
+}
+
+func (s *frequencySketch) estimate(uid string) uint8 {
+	est := uint8(sketchMaxCounter)
+	for i, j := range s.indexes(uid) {
+		est = min(est, s.counters[i][j])
+	}
+	return est
+}
+
+func (s *frequencySketch) reset() {
+	for i := range s.counters {
+		for j := range s.counters[i] {
+			s.counters[i][j] >>= 1
+		}
+	}
+	s.added /= 2
+}
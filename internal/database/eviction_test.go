@@ -0,0 +1,47 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLFU_VictimOrder проверяет порядок вытеснения LFU, включая равные частоты.
+func TestLFU_VictimOrder(t *testing.T) {
+	l := newLFU()
+	l.add("a")
+	l.add("b")
+	l.add("c")
+	l.touch("a")
+	l.touch("c")
+
+	victim, ok := l.victim()
+	require.True(t, ok)
+	assert.Equal(t, "b", victim)
+
+	l.remove("b")
+	victim, _ = l.victim()
+	assert.Equal(t, "a", victim, "при равной частоте вытесняется давно использованная запись")
+
+	l.remove("a")
+	l.remove("c")
+	_, ok = l.victim()
+	assert.False(t, ok)
+}
+
+// TestFrequencySketch_EstimateAndReset проверяет оценку частоты и старение счетчиков.
+func TestFrequencySketch_EstimateAndReset(t *testing.T) {
+	s := newFrequencySketch(16)
+	for range 5 {
+		s.increment("hot")
+	}
+	s.increment("cold")
+
+	assert.GreaterOrEqual(t, s.estimate("hot"), uint8(5))
+	assert.Greater(t, s.estimate("hot"), s.estimate("cold"))
+
+	before := s.estimate("hot")
+	s.reset()
+	assert.Equal(t, before/2, s.estimate("hot"), "старение делит счетчики пополам")
+}
@@ -0,0 +1,267 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"l1/internal/model"
+)
+
+// OrderSort задает порядок выдачи списка заказов.
+type OrderSort string
+
+const (
+	// SortDateDesc — сначала новые заказы (по умолчанию).
+	SortDateDesc OrderSort = "date_desc"
+	// SortDateAsc — сначала старые заказы.
+	SortDateAsc OrderSort = "date_asc"
+	// SortAmountDesc — по убыванию суммы оплаты.
+	SortAmountDesc OrderSort = "amount_desc"
+	// SortAmountAsc — по возрастанию суммы оплаты.
+	SortAmountAsc OrderSort = "amount_asc"
+)
+
+// Ограничения размера страницы списка заказов.
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// OrderFilter — условия отбора заказов. Пустые поля не учитываются.
+type OrderFilter struct {
+	CustomerID      string
+	TrackNumber     string
+	DeliveryService string
+	CreatedFrom     time.Time // включительно
+	CreatedTo       time.Time // не включительно
+	Bank            string
+	Currency        string
+	Brand           string // хотя бы один товар заказа этого бренда
+	NmID            int    // хотя бы один товар заказа с этим артикулом
+	Phone           string // телефон доставки в формате E.164 (validation.NormalizePhone)
+}
+
+// Match сообщает, подходит ли заказ под фильтр. Условия те же, что и в запросе
+// к БД; используется для отбора событий о новых заказах.
+func (f OrderFilter) Match(o *model.OrderData) bool {
+	switch {
+	case f.CustomerID != "" && o.CustomerID != f.CustomerID,
+		f.TrackNumber != "" && o.TrackNumber != f.TrackNumber,
+		f.DeliveryService != "" && o.DeliveryService != f.DeliveryService,
+		!f.CreatedFrom.IsZero() && o.DateCreated.Before(f.CreatedFrom),
+		!f.CreatedTo.IsZero() && !o.DateCreated.Before(f.CreatedTo),
+		f.Bank != "" && o.Payment.Bank != f.Bank,
+		f.Currency != "" && o.Payment.Currency != f.Currency,
+		f.Phone != "" && !phoneIs(o, f.Phone):
+		return false
+	}
+	// Как и в запросе к БД, бренд и артикул могут относиться к разным товарам
+	brandOK, nmOK := f.Brand == "", f.NmID == 0
+	for _, item := range o.Items {
+		brandOK = brandOK || item.Brand == f.Brand
+		nmOK = nmOK || item.NmID == f.NmID
+	}
+	return brandOK && nmOK
+}
+
+// phoneIs сообщает, совпадает ли нормализованный телефон доставки заказа с phone.
+func phoneIs(o *model.OrderData, phone string) bool {
+	p := phoneE164(o)
+	return p != nil && *p == phone
+}
+
+// ListOrdersQuery — запрос страницы списка заказов.
+type ListOrdersQuery struct {
+	Filter OrderFilter
+	Sort   OrderSort
+	Limit  int
+	// Cursor — значение NextCursor предыдущей страницы; пустой для первой страницы
+	Cursor string
+}
+
+// OrderPage — страница списка заказов. NextCursor пустой, если страница последняя.
+type OrderPage struct {
+	Orders     []*model.OrderData
+	NextCursor string
+}
+
+// withDefaults проверяет запрос и подставляет значения по умолчанию.
+func (q ListOrdersQuery) withDefaults() (ListOrdersQuery, error) {
+	switch q.Sort {
+	case "":
+		q.Sort = SortDateDesc
+	case SortDateDesc, SortDateAsc, SortAmountDesc, SortAmountAsc:
+	default:
+		return q, fmt.Errorf("%w: неизвестная сортировка %q", ErrInvalidQuery, q.Sort)
+	}
+	switch {
+	case q.Limit <= 0:
+		q.Limit = DefaultListLimit
+	case q.Limit > MaxListLimit:
+		q.Limit = MaxListLimit
+	}
+	return q, nil
+}
+
+// listCursor — позиция в списке: ключ сортировки и UID последнего заказа страницы.
+// Клиенту передается в виде непрозрачной строки.
+type listCursor struct {
+	Sort   OrderSort `json:"s"`
+	Date   time.Time `json:"d"`
+	Amount int       `json:"a"`
+	UID    string    `json:"u"`
+}
+
+func encodeCursor(c listCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string, sort OrderSort) (listCursor, error) {
+	var c listCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("%w: курсор поврежден", ErrInvalidQuery)
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.UID == "" {
+		return c, fmt.Errorf("%w: курсор поврежден", ErrInvalidQuery)
+	}
+	// Курсор одной сортировки не имеет смысла для другой
+	if c.Sort != sort {
+		return c, fmt.Errorf("%w: курсор получен для другой сортировки", ErrInvalidQuery)
+	}
+	return c, nil
+}
+
+// listQuery собирает запрос, выбирающий UID заказов страницы вместе с ключами сортировки.
+// Пагинация по ключу (keyset): следующая страница начинается строго после
+// пары (ключ сортировки, order_uid) последнего заказа предыдущей.
+func listQuery(q ListOrdersQuery, cursor *listCursor) (string, []any) {
+	var where []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	f := q.Filter
+	if f.CustomerID != "" {
+		where = append(where, "o.customer_id = "+arg(f.CustomerID))
+	}
+	if f.TrackNumber != "" {
+		where = append(where, "o.track_number = "+arg(f.TrackNumber))
+	}
+	if f.DeliveryService != "" {
+		where = append(where, "o.delivery_service = "+arg(f.DeliveryService))
+	}
+	if !f.CreatedFrom.IsZero() {
+		where = append(where, "o.date_created >= "+arg(f.CreatedFrom))
+	}
+	if !f.CreatedTo.IsZero() {
+		where = append(where, "o.date_created < "+arg(f.CreatedTo))
+	}
+	if f.Bank != "" {
+		where = append(where, "p.bank = "+arg(f.Bank))
+	}
+	if f.Currency != "" {
+		where = append(where, "p.currency = "+arg(f.Currency))
+	}
+	if f.Brand != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.brand = "+arg(f.Brand)+")")
+	}
+	if f.NmID != 0 {
+		where = append(where, "EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.nm_id = "+arg(f.NmID)+")")
+	}
+	if f.Phone != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM delivery d WHERE d.order_uid = o.order_uid AND d.phone_e164 = "+arg(f.Phone)+")")
+	}
+
+	column, dir, cmp := "o.date_created", "DESC", "<"
+	if q.Sort == SortAmountDesc || q.Sort == SortAmountAsc {
+		column = "p.amount"
+	}
+	if q.Sort == SortDateAsc || q.Sort == SortAmountAsc {
+		dir, cmp = "ASC", ">"
+	}
+	if cursor != nil {
+		var key any = cursor.Date
+		if column == "p.amount" {
+			key = cursor.Amount
+		}
+		where = append(where, fmt.Sprintf("(%s, o.order_uid) %s (%s, %s)", column, cmp, arg(key), arg(cursor.UID)))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("SELECT o.order_uid, o.date_created, p.amount FROM orders o JOIN payment p ON p.order_uid = o.order_uid AND p.is_current")
+	if len(where) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(where, " AND "))
+	}
+	// Берем на одну строку больше, чтобы узнать, есть ли следующая страница
+	fmt.Fprintf(&sb, " ORDER BY %s %s, o.order_uid %s LIMIT %s", column, dir, dir, arg(q.Limit+1))
+	return sb.String(), args
+}
+
+// ListOrders возвращает страницу заказов, подходящих под фильтр, в заданном порядке.
+// Некорректная сортировка или курсор — ErrInvalidQuery.
+func (p *PostgresStore) ListOrders(ctx context.Context, q ListOrdersQuery) (*OrderPage, error) {
+	q, err := q.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	var cursor *listCursor
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor, q.Sort)
+		if err != nil {
+			return nil, err
+		}
+		cursor = &c
+	}
+
+	sql, args := listQuery(q, cursor)
+	rows, err := p.DB.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, classifyReadError(fmt.Errorf("ошибка при поиске заказов: %w", err))
+	}
+	defer rows.Close()
+
+	var uids []string
+	var last listCursor
+	for rows.Next() {
+		c := listCursor{Sort: q.Sort}
+		if err := rows.Scan(&c.UID, &c.Date, &c.Amount); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования списка заказов: %w", err)
+		}
+		uids = append(uids, c.UID)
+		if len(uids) <= q.Limit {
+			last = c
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, classifyReadError(fmt.Errorf("ошибка при чтении списка заказов: %w", err))
+	}
+
+	page := &OrderPage{}
+	if len(uids) > q.Limit {
+		uids = uids[:q.Limit]
+		page.NextCursor = encodeCursor(last)
+	}
+
+	// Заказы целиком загружаются одним запросом в порядке страницы
+	page.Orders, err = p.GetOrdersByUIDs(ctx, uids)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetCustomerOrders возвращает страницу заказов покупателя. Фильтр по покупателю
+// заменяет одноименное поле q.Filter.
+func (p *PostgresStore) GetCustomerOrders(ctx context.Context, customerID string, q ListOrdersQuery) (*OrderPage, error) {
+	q.Filter.CustomerID = customerID
+	return p.ListOrders(ctx, q)
+}
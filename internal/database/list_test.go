@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"l1/internal/model"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPostgresStore_ListOrders_FirstPage проверяет фильтры, сортировку и курсор следующей страницы
+func TestPostgresStore_ListOrders_FirstPage(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	first := newTestOrderData("order-list-1")
+	second := newTestOrderData("order-list-2")
+
+	// 1. Запрос страницы: limit+1 строк, чтобы понять, есть ли продолжение
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`FROM orders o JOIN payment p ON p.order_uid = o.order_uid AND p.is_current WHERE o.customer_id = $1 AND p.currency = $2 AND EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.brand = $3) ORDER BY o.date_created DESC, o.order_uid DESC LIMIT $4`)).
+		WithArgs("test", "USD", "Vivienne Sabo", 3).
+		WillReturnRows(pgxmock.NewRows([]string{"order_uid", "date_created", "amount"}).
+			AddRow("order-list-1", created, 100).
+			AddRow("order-list-2", created.Add(-time.Hour), 200).
+			AddRow("order-list-3", created.Add(-2*time.Hour), 300))
+	// 2. Заказы страницы загружаются одним запросом
+	mock.ExpectQuery(`FROM orders o JOIN delivery d`).
+		WithArgs([]string{"order-list-1", "order-list-2"}).
+		WillReturnRows(joinedOrderRows(t, second, first))
+
+	page, err := store.ListOrders(ctx, ListOrdersQuery{
+		Filter: OrderFilter{CustomerID: "test", Currency: "USD", Brand: "Vivienne Sabo"},
+		Limit:  2,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, page.Orders, 2)
+	assert.Equal(t, "order-list-1", page.Orders[0].OrderUID)
+	assert.Equal(t, "order-list-2", page.Orders[1].OrderUID)
+	require.NotEmpty(t, page.NextCursor)
+
+	cursor, err := decodeCursor(page.NextCursor, SortDateDesc)
+	require.NoError(t, err)
+	assert.Equal(t, "order-list-2", cursor.UID)
+	assert.True(t, created.Add(-time.Hour).Equal(cursor.Date))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresStore_ListOrders_NextPage проверяет продолжение выдачи по курсору
+func TestPostgresStore_ListOrders_NextPage(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	cursor := encodeCursor(listCursor{Sort: SortAmountAsc, Amount: 200, UID: "order-list-2"})
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`WHERE (p.amount, o.order_uid) > ($1, $2) ORDER BY p.amount ASC, o.order_uid ASC LIMIT $3`)).
+		WithArgs(200, "order-list-2", DefaultListLimit+1).
+		WillReturnRows(pgxmock.NewRows([]string{"order_uid", "date_created", "amount"}).
+			AddRow("order-list-3", time.Now(), 300))
+	mock.ExpectQuery(`FROM orders o JOIN delivery d`).
+		WithArgs([]string{"order-list-3"}).
+		WillReturnRows(joinedOrderRows(t, newTestOrderData("order-list-3")))
+
+	page, err := store.ListOrders(ctx, ListOrdersQuery{Sort: SortAmountAsc, Cursor: cursor})
+	require.NoError(t, err)
+
+	require.Len(t, page.Orders, 1)
+	assert.Empty(t, page.NextCursor, "последняя страница не должна возвращать курсор")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresStore_ListOrders_Phone проверяет поиск по нормализованному телефону доставки
+func TestPostgresStore_ListOrders_Phone(t *testing.T) {
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`WHERE EXISTS (SELECT 1 FROM delivery d WHERE d.order_uid = o.order_uid AND d.phone_e164 = $1) ORDER BY`)).
+		WithArgs("+79161234567", DefaultListLimit+1).
+		WillReturnRows(pgxmock.NewRows([]string{"order_uid", "date_created", "amount"}))
+
+	page, err := store.ListOrders(context.Background(), ListOrdersQuery{Filter: OrderFilter{Phone: "+79161234567"}})
+	require.NoError(t, err)
+
+	assert.Empty(t, page.Orders)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresStore_ListOrders_InvalidQuery проверяет отказ без обращения к БД
+func TestPostgresStore_ListOrders_InvalidQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		q    ListOrdersQuery
+	}{
+		{name: "неизвестная сортировка", q: ListOrdersQuery{Sort: "price"}},
+		{name: "поврежденный курсор", q: ListOrdersQuery{Cursor: "not-a-cursor!"}},
+		{name: "курсор другой сортировки", q: ListOrdersQuery{
+			Sort:   SortDateAsc,
+			Cursor: encodeCursor(listCursor{Sort: SortDateDesc, UID: "uid"}),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, mock := newMockPostgresStore(t)
+			defer mock.Close()
+
+			page, err := store.ListOrders(context.Background(), tt.q)
+
+			assert.Nil(t, page)
+			assert.ErrorIs(t, err, ErrInvalidQuery)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestOrderFilter_Match проверяет отбор заказа фильтром без обращения к БД
+func TestOrderFilter_Match(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	order := &model.OrderData{
+		CustomerID: "test", DateCreated: created,
+		Delivery: model.Delivery{Phone: "8 (916) 123-45-67"},
+		Locale:   "ru",
+		Payment:  model.Payment{Currency: "USD", Bank: "alpha"},
+		Items:    []model.Item{{NmID: 1, Brand: "A"}, {NmID: 2, Brand: "B"}},
+	}
+
+	tests := []struct {
+		name   string
+		filter OrderFilter
+		want   bool
+	}{
+		{"пустой фильтр", OrderFilter{}, true},
+		{"покупатель и валюта", OrderFilter{CustomerID: "test", Currency: "USD"}, true},
+		{"другой покупатель", OrderFilter{CustomerID: "other"}, false},
+		{"другой банк", OrderFilter{Bank: "sber"}, false},
+		{"начало периода включительно", OrderFilter{CreatedFrom: created}, true},
+		{"конец периода не включительно", OrderFilter{CreatedTo: created}, false},
+		{"бренд и артикул разных товаров", OrderFilter{Brand: "A", NmID: 2}, true},
+		{"нет товара бренда", OrderFilter{Brand: "C"}, false},
+		{"телефон в E.164", OrderFilter{Phone: "+79161234567"}, true},
+		{"другой телефон", OrderFilter{Phone: "+79160000000"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Match(order))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"l1/internal/model"
+)
+
+// PaymentAttempt — попытка оплаты заказа. Каждая новая транзакция по заказу
+// сохраняется отдельной попыткой; текущая (Current) отдается в составе заказа.
+type PaymentAttempt struct {
+	model.Payment
+	Current   bool      `json:"current"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GetPaymentAttempts возвращает все попытки оплаты заказа, начиная с первой.
+// У сохраненного заказа всегда есть хотя бы одна попытка, поэтому пустой
+// результат означает, что заказа нет (ErrNotFound).
+func (p *PostgresStore) GetPaymentAttempts(ctx context.Context, orderUID string) ([]PaymentAttempt, error) {
+	rows, err := p.DB.Query(ctx,
+		`SELECT transaction_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee,
+		        is_current, created_at
+		 FROM payment WHERE order_uid = $1 ORDER BY id`,
+		orderUID,
+	)
+	if err != nil {
+		return nil, classifyReadError(fmt.Errorf("ошибка при получении оплат заказа %s: %w", orderUID, err))
+	}
+	defer rows.Close()
+
+	var attempts []PaymentAttempt
+	for rows.Next() {
+		var a PaymentAttempt
+		if err := rows.Scan(
+			&a.Transaction, &a.RequestID, &a.Currency, &a.Provider, &a.Amount, &a.PaymentDt, &a.Bank,
+			&a.DeliveryCost, &a.GoodsTotal, &a.CustomFee, &a.Current, &a.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании оплаты заказа %s: %w", orderUID, err)
+		}
+		attempts = append(attempts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, classifyReadError(fmt.Errorf("ошибка при чтении оплат заказа %s: %w", orderUID, err))
+	}
+	if len(attempts) == 0 {
+		return nil, withKind(ErrNotFound, fmt.Errorf("заказ с UID %s не найден: %w", orderUID, pgx.ErrNoRows))
+	}
+	return attempts, nil
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var paymentAttemptColumns = []string{
+	"transaction_id", "request_id", "currency", "provider", "amount", "payment_dt", "bank",
+	"delivery_cost", "goods_total", "custom_fee", "is_current", "created_at",
+}
+
+// TestPostgresStore_GetPaymentAttempts проверяет загрузку истории оплат заказа
+func TestPostgresStore_GetPaymentAttempts(t *testing.T) {
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	first := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Первая попытка не прошла, вторая с другой транзакцией — текущая
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM payment WHERE order_uid = $1 ORDER BY id`)).
+		WithArgs("order-1").
+		WillReturnRows(pgxmock.NewRows(paymentAttemptColumns).
+			AddRow("tx-1", "", "USD", "wbpay", 1817, int64(1637907727), "alpha", 1500, 317, 0, false, first).
+			AddRow("tx-2", "", "USD", "wbpay", 1817, int64(1637907827), "sber", 1500, 317, 0, true, first.Add(time.Minute)))
+
+	attempts, err := store.GetPaymentAttempts(context.Background(), "order-1")
+
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	assert.Equal(t, "tx-1", attempts[0].Transaction)
+	assert.False(t, attempts[0].Current)
+	assert.Equal(t, "tx-2", attempts[1].Transaction)
+	assert.Equal(t, "sber", attempts[1].Bank)
+	assert.True(t, attempts[1].Current)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresStore_GetPaymentAttempts_NotFound проверяет ответ для несуществующего заказа
+func TestPostgresStore_GetPaymentAttempts_NotFound(t *testing.T) {
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM payment WHERE order_uid = $1`)).
+		WithArgs("missing").
+		WillReturnRows(pgxmock.NewRows(paymentAttemptColumns))
+
+	_, err := store.GetPaymentAttempts(context.Background(), "missing")
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,6 +2,10 @@ package database
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -10,6 +14,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"l1/internal/model"
+	"l1/internal/validation"
 )
 
 // DBPoolIface определяет методы, которые Store использует для взаимодействия с базой данных.
@@ -17,16 +22,47 @@ type DBPoolIface interface {
 	Begin(ctx context.Context) (pgx.Tx, error)
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Ping(ctx context.Context) error
 	Close()
 }
 
+// ConflictMode определяет, что делать при повторном сохранении заказа с тем же UID,
+// но с другими данными.
+type ConflictMode string
+
+const (
+	// ConflictUpsert — перезаписать заказ новыми данными (используется по умолчанию).
+	ConflictUpsert ConflictMode = "upsert"
+	// ConflictReject — отклонить сохранение с ошибкой ErrOrderConflict.
+	ConflictReject ConflictMode = "reject"
+)
+
+// LoadStrategy определяет, как GetOrderByUID загружает заказ из БД.
+type LoadStrategy string
+
+const (
+	// LoadSeparate — отдельный запрос к каждой таблице (используется, если стратегия
+	// в StoreOptions не задана).
+	LoadSeparate LoadStrategy = "separate"
+	// LoadJoined — один запрос с JOIN и агрегацией товаров в JSON (по умолчанию
+	// в конфигурации сервиса, ORDER_LOAD_STRATEGY).
+	LoadJoined LoadStrategy = "joined"
+)
+
+// StoreOptions — настройки PostgresStore.
+type StoreOptions struct {
+	ConflictMode ConflictMode
+	LoadStrategy LoadStrategy
+}
+
 // PostgresStore реализует интерфейс OrderDB.
 type PostgresStore struct {
 	DB DBPoolIface
+	StoreOptions
 }
 
 // NewPostgresStore создает подключение и возвращает *PostgresStore.
-func NewPostgresStore(connString string) (*PostgresStore, error) {
+func NewPostgresStore(connString string, opts StoreOptions) (*PostgresStore, error) {
 	dbpool, err := pgxpool.New(context.Background(), connString)
 	if err != nil {
 		return nil, fmt.Errorf("не удалось подключиться к базе данных: %w", err)
@@ -37,7 +73,8 @@ func NewPostgresStore(connString string) (*PostgresStore, error) {
 	log.Println("Успешное подключение к PostgreSQL!")
 
 	return &PostgresStore{
-		DB: dbpool,
+		DB:           dbpool,
+		StoreOptions: opts,
 	}, nil
 }
 
@@ -45,8 +82,51 @@ func (p *PostgresStore) Close() {
 	p.DB.Close()
 }
 
+// Ping проверяет соединение с базой данных.
+func (p *PostgresStore) Ping(ctx context.Context) error {
+	if err := p.DB.Ping(ctx); err != nil {
+		return fmt.Errorf("база данных недоступна: %w", err)
+	}
+	return nil
+}
+
+// payloadHash вычисляет хэш содержимого заказа, по которому распознаются повторные доставки.
+func payloadHash(order model.OrderData) (string, error) {
+	// Время приводим к UTC, чтобы один и тот же момент в разных зонах давал одинаковый хэш
+	order.DateCreated = order.DateCreated.UTC()
+	data, err := json.Marshal(order)
+	if err != nil {
+		return "", fmt.Errorf("не удалось сериализовать заказ: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// resolveExisting решает, что делать с заказом, который уже есть в БД:
+// пропустить (skip), если данные не изменились, вернуть ErrOrderConflict
+// или перезаписать (skip == false, err == nil).
+func (p *PostgresStore) resolveExisting(orderUID, hash string, existingHash *string) (skip bool, err error) {
+	if existingHash != nil && *existingHash == hash {
+		log.Printf("Заказ %s уже сохранен с теми же данными, пропускаем", orderUID)
+		return true, nil
+	}
+	if p.ConflictMode == ConflictReject {
+		return false, fmt.Errorf("%w: заказ %s уже сохранен с другими данными", ErrOrderConflict, orderUID)
+	}
+	log.Printf("Заказ %s уже сохранен с другими данными, перезаписываем", orderUID)
+	return false, nil
+}
+
 // SaveOrder сохраняет все части заказа в рамках одной транзакции.
+// Сохранение идемпотентно: повторная доставка того же заказа ничего не меняет,
+// а заказ с тем же UID, но другими данными перезаписывается или отклоняется
+// с ErrOrderConflict в зависимости от ConflictMode.
 func (p *PostgresStore) SaveOrder(ctx context.Context, order model.OrderData) (err error) {
+	hash, err := payloadHash(order)
+	if err != nil {
+		return err
+	}
+
 	tx, err := p.DB.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("не удалось начать транзакцию: %w", err)
@@ -61,45 +141,136 @@ func (p *PostgresStore) SaveOrder(ctx context.Context, order model.OrderData) (e
 		}
 	}()
 
+	// 0. Блокируем UID заказа до конца транзакции. FOR UPDATE ниже ничего не блокирует,
+	// пока строки еще нет, и без этой блокировки две одновременные первые доставки
+	// одного заказа записали бы его обе, минуя ConflictMode.
+	if _, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, order.OrderUID); err != nil {
+		return fmt.Errorf("не удалось заблокировать заказ: %w", err)
+	}
+
+	// Проверяем, не сохранен ли уже заказ (и блокируем строку до конца транзакции)
+	var existingHash *string
+	exists := true
+	err = tx.QueryRow(ctx, `SELECT payload_hash FROM orders WHERE order_uid = $1 FOR UPDATE`, order.OrderUID).Scan(&existingHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		exists = false
+	} else if err != nil {
+		return fmt.Errorf("ошибка при проверке существования заказа: %w", err)
+	}
+
+	if exists {
+		skip, conflictErr := p.resolveExisting(order.OrderUID, hash, existingHash)
+		if conflictErr != nil {
+			return conflictErr
+		}
+		if skip {
+			return tx.Commit(ctx)
+		}
+	}
+
+	if err = writeOrder(ctx, tx, order, hash, exists); err != nil {
+		return err
+	}
+
+	// Если все прошло успешно, коммитим транзакцию.
+	return tx.Commit(ctx)
+}
+
+// phoneE164 возвращает телефон доставки в формате E.164 для поиска по нему или nil,
+// если номер нормализовать не удалось: такой заказ по телефону не находится.
+func phoneE164(order *model.OrderData) *string {
+	phone, err := validation.NormalizePhone(order.Delivery.Phone, validation.CountryOf(order))
+	if err != nil {
+		return nil
+	}
+	return &phone
+}
+
+// deliveryCountry возвращает код страны доставки в верхнем регистре. Значение,
+// не похожее на код ISO 3166-1 alpha-2, не сохраняется: страна считается неизвестной.
+func deliveryCountry(order *model.OrderData) string {
+	code, ok := validation.NormalizeCountry(order.Delivery.Country)
+	if !ok {
+		return ""
+	}
+	return code
+}
+
+// writeOrder записывает (или перезаписывает) все части заказа в рамках транзакции tx.
+// replaceItems удаляет ранее сохраненные товары заказа перед вставкой новых.
+func writeOrder(ctx context.Context, tx pgx.Tx, order model.OrderData, hash string, replaceItems bool) error {
 	// 1. Сохраняем информацию о доставке
-	_, err = tx.Exec(ctx,
-		`INSERT INTO delivery (order_uid, name, phone, zip, city, address, region, email)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+	_, err := tx.Exec(ctx,
+		`INSERT INTO delivery (order_uid, name, phone, zip, city, address, region, email, country, phone_e164)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		 ON CONFLICT (order_uid) DO UPDATE SET
+		     name = EXCLUDED.name, phone = EXCLUDED.phone, zip = EXCLUDED.zip, city = EXCLUDED.city,
+		     address = EXCLUDED.address, region = EXCLUDED.region, email = EXCLUDED.email,
+		     country = EXCLUDED.country, phone_e164 = EXCLUDED.phone_e164`,
 		order.OrderUID, order.Delivery.Name, order.Delivery.Phone, order.Delivery.Zip,
 		order.Delivery.City, order.Delivery.Address, order.Delivery.Region, order.Delivery.Email,
+		deliveryCountry(&order), phoneE164(&order),
 	)
 	if err != nil {
 		return fmt.Errorf("ошибка при сохранении доставки: %w", err)
 	}
 
-	// 2. Сохраняем информацию об оплате
+	// 2. Сохраняем основную информацию о заказе
 	_, err = tx.Exec(ctx,
-		`INSERT INTO payment (transaction_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
-		order.Payment.Transaction, order.Payment.RequestID, order.Payment.Currency, order.Payment.Provider,
-		order.Payment.Amount, order.Payment.PaymentDt, order.Payment.Bank, order.Payment.DeliveryCost,
-		order.Payment.GoodsTotal, order.Payment.CustomFee,
+		`INSERT INTO orders (order_uid, track_number, entry, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, locale, internal_signature, payload_hash)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		 ON CONFLICT (order_uid) DO UPDATE SET
+		     track_number = EXCLUDED.track_number, entry = EXCLUDED.entry, customer_id = EXCLUDED.customer_id,
+		     delivery_service = EXCLUDED.delivery_service, shardkey = EXCLUDED.shardkey, sm_id = EXCLUDED.sm_id,
+		     date_created = EXCLUDED.date_created, oof_shard = EXCLUDED.oof_shard, locale = EXCLUDED.locale,
+		     internal_signature = EXCLUDED.internal_signature, payload_hash = EXCLUDED.payload_hash`,
+		order.OrderUID, order.TrackNumber, order.Entry, order.CustomerID, order.DeliveryService,
+		order.Shardkey, order.SmID, order.DateCreated, order.OofShard, order.Locale, order.InternalSignature,
+		hash,
 	)
 	if err != nil {
-		return fmt.Errorf("ошибка при сохранении оплаты: %w", err)
+		return fmt.Errorf("ошибка при сохранении заказа: %w", err)
 	}
 
-	// 3. Сохраняем основную информацию о заказе
+	// 3. Сохраняем оплату. Оплата с новой транзакцией — новая попытка оплаты заказа:
+	// она становится текущей, а прежние попытки остаются в истории
 	_, err = tx.Exec(ctx,
-		`INSERT INTO orders (order_uid, track_number, entry, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, locale, internal_signature)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
-		order.OrderUID, order.TrackNumber, order.Entry, order.CustomerID, order.DeliveryService,
-		order.Shardkey, order.SmID, order.DateCreated, order.OofShard, order.Locale, order.InternalSignature,
+		`UPDATE payment SET is_current = false WHERE order_uid = $1 AND transaction_id <> $2 AND is_current`,
+		order.OrderUID, order.Payment.Transaction,
 	)
 	if err != nil {
-		return fmt.Errorf("ошибка при сохранении заказа: %w", err)
+		return fmt.Errorf("ошибка при сохранении оплаты: %w", err)
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO payment (order_uid, transaction_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		 ON CONFLICT (order_uid, transaction_id) DO UPDATE SET
+		     request_id = EXCLUDED.request_id, currency = EXCLUDED.currency, provider = EXCLUDED.provider,
+		     amount = EXCLUDED.amount, payment_dt = EXCLUDED.payment_dt, bank = EXCLUDED.bank,
+		     delivery_cost = EXCLUDED.delivery_cost, goods_total = EXCLUDED.goods_total, custom_fee = EXCLUDED.custom_fee,
+		     is_current = true`,
+		order.OrderUID, order.Payment.Transaction, order.Payment.RequestID, order.Payment.Currency, order.Payment.Provider,
+		order.Payment.Amount, order.Payment.PaymentDt, order.Payment.Bank, order.Payment.DeliveryCost,
+		order.Payment.GoodsTotal, order.Payment.CustomFee,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении оплаты: %w", err)
 	}
 
-	// 4. Сохраняем товары
+	// 4. Сохраняем товары. При перезаписи старый список товаров заменяется целиком.
+	if replaceItems {
+		if _, err := tx.Exec(ctx, `DELETE FROM items WHERE order_uid = $1`, order.OrderUID); err != nil {
+			return fmt.Errorf("ошибка при удалении старых товаров заказа: %w", err)
+		}
+	}
 	for _, item := range order.Items {
-		_, err = tx.Exec(ctx,
+		_, err := tx.Exec(ctx,
 			`INSERT INTO items (order_uid, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			 ON CONFLICT (order_uid, chrt_id) DO UPDATE SET
+			     track_number = EXCLUDED.track_number, price = EXCLUDED.price, rid = EXCLUDED.rid, name = EXCLUDED.name,
+			     sale = EXCLUDED.sale, size = EXCLUDED.size, total_price = EXCLUDED.total_price, nm_id = EXCLUDED.nm_id,
+			     brand = EXCLUDED.brand, status = EXCLUDED.status`,
 			order.OrderUID, item.ChrtID, item.TrackNumber, item.Price, item.Rid, item.Name,
 			item.Sale, item.Size, item.TotalPrice, item.NmID, item.Brand, item.Status,
 		)
@@ -108,12 +279,26 @@ func (p *PostgresStore) SaveOrder(ctx context.Context, order model.OrderData) (e
 		}
 	}
 
-	// Если все прошло успешно, коммитим транзакцию.
-	return tx.Commit(ctx)
+	return nil
 }
 
 // GetOrderByUID получает полную информацию о заказе по его UID.
 func (p *PostgresStore) GetOrderByUID(ctx context.Context, orderUID string) (*model.OrderData, error) {
+	if p.LoadStrategy == LoadJoined {
+		orders, err := p.GetOrdersByUIDs(ctx, []string{orderUID})
+		if err != nil {
+			return nil, err
+		}
+		if len(orders) == 0 {
+			return nil, withKind(ErrNotFound, fmt.Errorf("заказ с UID %s не найден: %w", orderUID, pgx.ErrNoRows))
+		}
+		return orders[0], nil
+	}
+	return p.getOrderSeparate(ctx, orderUID)
+}
+
+// getOrderSeparate загружает заказ четырьмя запросами — по одному на таблицу.
+func (p *PostgresStore) getOrderSeparate(ctx context.Context, orderUID string) (*model.OrderData, error) {
 	order := &model.OrderData{}
 	err := p.DB.QueryRow(ctx,
 		`SELECT order_uid, track_number, entry, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard, locale, internal_signature
@@ -123,25 +308,28 @@ func (p *PostgresStore) GetOrderByUID(ctx context.Context, orderUID string) (*mo
 		&order.OrderUID, &order.TrackNumber, &order.Entry, &order.CustomerID, &order.DeliveryService,
 		&order.Shardkey, &order.SmID, &order.DateCreated, &order.OofShard, &order.Locale, &order.InternalSignature,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, withKind(ErrNotFound, fmt.Errorf("заказ с UID %s не найден: %w", orderUID, err))
+	}
 	if err != nil {
-		return nil, fmt.Errorf("заказ с UID %s не найден: %w", orderUID, err)
+		return nil, classifyReadError(fmt.Errorf("ошибка при получении заказа %s: %w", orderUID, err))
 	}
 
 	// 3. Получаем информацию о доставке
 	err = p.DB.QueryRow(ctx,
-		`SELECT name, phone, zip, city, address, region, email FROM delivery WHERE order_uid = $1`,
+		`SELECT name, phone, zip, city, address, region, email, country FROM delivery WHERE order_uid = $1`,
 		orderUID,
 	).Scan(
 		&order.Delivery.Name, &order.Delivery.Phone, &order.Delivery.Zip, &order.Delivery.City,
-		&order.Delivery.Address, &order.Delivery.Region, &order.Delivery.Email,
+		&order.Delivery.Address, &order.Delivery.Region, &order.Delivery.Email, &order.Delivery.Country,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("не найдена информация о доставке для заказа %s: %w", orderUID, err)
+		return nil, classifyReadError(fmt.Errorf("не найдена информация о доставке для заказа %s: %w", orderUID, err))
 	}
 
-	// 4. Получаем информацию об оплате
+	// 4. Получаем текущую оплату
 	err = p.DB.QueryRow(ctx,
-		`SELECT transaction_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee FROM payment WHERE transaction_id = $1`,
+		`SELECT transaction_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee FROM payment WHERE order_uid = $1 AND is_current`,
 		orderUID,
 	).Scan(
 		&order.Payment.Transaction, &order.Payment.RequestID, &order.Payment.Currency, &order.Payment.Provider,
@@ -149,7 +337,7 @@ func (p *PostgresStore) GetOrderByUID(ctx context.Context, orderUID string) (*mo
 		&order.Payment.GoodsTotal, &order.Payment.CustomFee,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("не найдена информация об оплате для заказа %s: %w", orderUID, err)
+		return nil, classifyReadError(fmt.Errorf("не найдена информация об оплате для заказа %s: %w", orderUID, err))
 	}
 
 	// 5. Получаем список товаров
@@ -159,7 +347,7 @@ func (p *PostgresStore) GetOrderByUID(ctx context.Context, orderUID string) (*mo
 		orderUID,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении товаров для заказа %s: %w", orderUID, err)
+		return nil, classifyReadError(fmt.Errorf("ошибка при получении товаров для заказа %s: %w", orderUID, err))
 	}
 	defer rows.Close()
 
@@ -173,29 +361,140 @@ func (p *PostgresStore) GetOrderByUID(ctx context.Context, orderUID string) (*mo
 		}
 		order.Items = append(order.Items, item)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, classifyReadError(fmt.Errorf("ошибка при чтении товаров заказа %s: %w", orderUID, err))
+	}
 
 	return order, nil
 }
 
-// GetRecentOrderUIDs — метод для прогрева кэша.
-func (p *PostgresStore) GetRecentOrderUIDs(ctx context.Context, since time.Time) ([]string, error) {
-	rows, err := p.DB.Query(ctx, `
-       SELECT order_uid 
-       FROM orders 
-       WHERE date_created >= $1
-       `, since)
+// ordersByUIDsQuery загружает заказы целиком за один запрос: доставка и текущая
+// оплата присоединяются к заказу, а товары собираются в JSON-массив.
+const ordersByUIDsQuery = `
+	SELECT o.order_uid, o.track_number, o.entry, o.customer_id, o.delivery_service, o.shardkey, o.sm_id,
+	       o.date_created, o.oof_shard, o.locale, o.internal_signature,
+	       d.name, d.phone, d.zip, d.city, d.address, d.region, d.email, d.country,
+	       p.transaction_id, p.request_id, p.currency, p.provider, p.amount, p.payment_dt, p.bank,
+	       p.delivery_cost, p.goods_total, p.custom_fee,
+	       COALESCE(i.items, '[]'::json)
+	FROM orders o
+	JOIN delivery d ON d.order_uid = o.order_uid
+	JOIN payment p ON p.order_uid = o.order_uid AND p.is_current
+	LEFT JOIN LATERAL (
+	    SELECT json_agg(json_build_object(
+	               'chrt_id', it.chrt_id, 'track_number', it.track_number, 'price', it.price, 'rid', it.rid,
+	               'name', it.name, 'sale', it.sale, 'size', it.size, 'total_price', it.total_price,
+	               'nm_id', it.nm_id, 'brand', it.brand, 'status', it.status
+	           ) ORDER BY it.id) AS items
+	    FROM items it WHERE it.order_uid = o.order_uid
+	) i ON true
+	WHERE o.order_uid = ANY($1)`
+
+// GetOrdersByUIDs загружает несколько заказов одним запросом. Заказы возвращаются
+// в порядке uids; отсутствующие в БД пропускаются.
+func (p *PostgresStore) GetOrdersByUIDs(ctx context.Context, uids []string) ([]*model.OrderData, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+
+	rows, err := p.DB.Query(ctx, ordersByUIDsQuery, uids)
+	if err != nil {
+		return nil, classifyReadError(fmt.Errorf("ошибка при получении заказов: %w", err))
+	}
+	defer rows.Close()
+
+	found := make(map[string]*model.OrderData, len(uids))
+	for rows.Next() {
+		order := &model.OrderData{}
+		var items []byte
+		if err := rows.Scan(
+			&order.OrderUID, &order.TrackNumber, &order.Entry, &order.CustomerID, &order.DeliveryService,
+			&order.Shardkey, &order.SmID, &order.DateCreated, &order.OofShard, &order.Locale, &order.InternalSignature,
+			&order.Delivery.Name, &order.Delivery.Phone, &order.Delivery.Zip, &order.Delivery.City,
+			&order.Delivery.Address, &order.Delivery.Region, &order.Delivery.Email, &order.Delivery.Country,
+			&order.Payment.Transaction, &order.Payment.RequestID, &order.Payment.Currency, &order.Payment.Provider,
+			&order.Payment.Amount, &order.Payment.PaymentDt, &order.Payment.Bank, &order.Payment.DeliveryCost,
+			&order.Payment.GoodsTotal, &order.Payment.CustomFee,
+			&items,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании заказа: %w", err)
+		}
+		if err := json.Unmarshal(items, &order.Items); err != nil {
+			return nil, fmt.Errorf("ошибка при разборе товаров заказа %s: %w", order.OrderUID, err)
+		}
+		if len(order.Items) == 0 {
+			order.Items = nil // как при загрузке отдельными запросами
+		}
+		found[order.OrderUID] = order
+	}
+	if err := rows.Err(); err != nil {
+		return nil, classifyReadError(fmt.Errorf("ошибка при чтении заказов: %w", err))
+	}
+
+	orders := make([]*model.OrderData, 0, len(found))
+	for _, uid := range uids {
+		if order, ok := found[uid]; ok {
+			orders = append(orders, order)
+			delete(found, uid) // повторный UID во входных данных не дублирует заказ
+		}
+	}
+	return orders, nil
+}
+
+// GetOrderByTrackNumber получает заказ по трек-номеру. Если трек-номер есть
+// у нескольких заказов, возвращается самый новый.
+func (p *PostgresStore) GetOrderByTrackNumber(ctx context.Context, track string) (*model.OrderData, error) {
+	var uid string
+	err := p.DB.QueryRow(ctx,
+		`SELECT order_uid FROM orders WHERE track_number = $1 ORDER BY date_created DESC, order_uid DESC LIMIT 1`,
+		track,
+	).Scan(&uid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, withKind(ErrNotFound, fmt.Errorf("заказ с трек-номером %s не найден: %w", track, err))
+	}
+	if err != nil {
+		return nil, classifyReadError(fmt.Errorf("ошибка при поиске заказа по трек-номеру %s: %w", track, err))
+	}
+	return p.GetOrderByUID(ctx, uid)
+}
+
+// OrderKey — позиция заказа в выборке для прогрева: время создания и UID.
+type OrderKey struct {
+	UID         string
+	DateCreated time.Time
+}
+
+// GetRecentOrderUIDs — метод для прогрева кэша. Возвращает до limit ключей заказов,
+// созданных не раньше since, начиная с самых новых. Следующая страница запрашивается
+// с after — последним ключом предыдущей (nil для первой страницы).
+func (p *PostgresStore) GetRecentOrderUIDs(ctx context.Context, since time.Time, after *OrderKey, limit int) ([]OrderKey, error) {
+	query := `
+       SELECT order_uid, date_created
+       FROM orders
+       WHERE date_created >= $1`
+	args := []any{since}
+	if after != nil {
+		query += ` AND (date_created, order_uid) < ($2, $3)`
+		args = append(args, after.DateCreated, after.UID)
+	}
+	query += fmt.Sprintf(`
+       ORDER BY date_created DESC, order_uid DESC
+       LIMIT $%d`, len(args)+1)
+	args = append(args, limit)
+
+	rows, err := p.DB.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("не удалось получить список заказов: %w", err)
 	}
 	defer rows.Close()
 
-	var uids []string
+	var keys []OrderKey
 	for rows.Next() {
-		var uid string
-		if err := rows.Scan(&uid); err != nil {
+		var key OrderKey
+		if err := rows.Scan(&key.UID, &key.DateCreated); err != nil {
 			return nil, fmt.Errorf("ошибка при чтении uid: %w", err)
 		}
-		uids = append(uids, uid)
+		keys = append(keys, key)
 	}
-	return uids, rows.Err()
+	return keys, rows.Err()
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"l1/internal/model"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -59,6 +61,86 @@ func newTestOrderData(uid string) model.OrderData {
 	return orderCopy
 }
 
+// deliveryArgs возвращает аргументы INSERT в delivery для заказа.
+func deliveryArgs(order model.OrderData) []any {
+	return []any{
+		order.OrderUID, order.Delivery.Name, order.Delivery.Phone, order.Delivery.Zip,
+		order.Delivery.City, order.Delivery.Address, order.Delivery.Region, order.Delivery.Email,
+		deliveryCountry(&order), phoneE164(&order),
+	}
+}
+
+// expectPaymentWrite ожидает сохранение оплаты заказа: прежние попытки с другой
+// транзакцией перестают быть текущими, оплата заказа вставляется или обновляется.
+func expectPaymentWrite(mock pgxmock.PgxPoolIface, order model.OrderData) {
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE payment SET is_current = false WHERE order_uid = $1 AND transaction_id <> $2 AND is_current`)).
+		WithArgs(order.OrderUID, order.Payment.Transaction).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO payment (order_uid, transaction_id,`)).
+		WithArgs(
+			order.OrderUID, order.Payment.Transaction, order.Payment.RequestID, order.Payment.Currency, order.Payment.Provider,
+			order.Payment.Amount, order.Payment.PaymentDt, order.Payment.Bank, order.Payment.DeliveryCost,
+			order.Payment.GoodsTotal, order.Payment.CustomFee,
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
+// expectOrderLookup ожидает блокировку UID и проверку существования заказа;
+// existingHash == nil означает, что заказа еще нет в БД.
+func expectOrderLookup(mock pgxmock.PgxPoolIface, uid string, existingHash *string) {
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext($1))`)).
+		WithArgs(uid).
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	q := mock.ExpectQuery(regexp.QuoteMeta(`SELECT payload_hash FROM orders WHERE order_uid = $1 FOR UPDATE`)).
+		WithArgs(uid)
+	if existingHash == nil {
+		q.WillReturnError(pgx.ErrNoRows)
+		return
+	}
+	q.WillReturnRows(pgxmock.NewRows([]string{"payload_hash"}).AddRow(existingHash))
+}
+
+// anyArgs возвращает n аргументов, совпадающих с любым значением.
+func anyArgs(n int) []any {
+	args := make([]any, n)
+	for i := range args {
+		args[i] = pgxmock.AnyArg()
+	}
+	return args
+}
+
+// mustPayloadHash вычисляет хэш заказа для тестов.
+func mustPayloadHash(t *testing.T, order model.OrderData) string {
+	t.Helper()
+	hash, err := payloadHash(order)
+	require.NoError(t, err)
+	return hash
+}
+
+// TestPhoneE164 проверяет телефон, который сохраняется для поиска
+func TestPhoneE164(t *testing.T) {
+	order := newTestOrderData("order-phone")
+	order.Locale = "ru"
+	order.Delivery.Phone = "8 (916) 123-45-67"
+	require.NotNil(t, phoneE164(&order))
+	assert.Equal(t, "+79161234567", *phoneE164(&order))
+
+	// Номер без кода страны при неизвестной стране не нормализуется
+	order.Locale = "en"
+	assert.Nil(t, phoneE164(&order))
+}
+
+// TestDeliveryCountry проверяет код страны, который сохраняется в delivery.country
+func TestDeliveryCountry(t *testing.T) {
+	order := newTestOrderData("order-country")
+	order.Delivery.Country = "ru"
+	assert.Equal(t, "RU", deliveryCountry(&order))
+
+	// В колонку VARCHAR(2) не пишется значение, не похожее на код страны
+	order.Delivery.Country = "Russia"
+	assert.Empty(t, deliveryCountry(&order))
+}
+
 // TestPostgresStore_SaveOrder_Success проверяет успешное сохранение заказа
 func TestPostgresStore_SaveOrder_Success(t *testing.T) {
 	ctx := context.Background()
@@ -66,35 +148,26 @@ func TestPostgresStore_SaveOrder_Success(t *testing.T) {
 	defer mock.Close()
 	order := newTestOrderData("order-success")
 
-	// 1. Ожидаем начала транзакции
+	// 1. Ожидаем начала транзакции и проверку существования заказа
 	mock.ExpectBegin()
+	expectOrderLookup(mock, order.OrderUID, nil)
 
 	// 2. Ожидаем INSERT в delivery
 	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO delivery`)).
-		WithArgs(
-			order.OrderUID, order.Delivery.Name, order.Delivery.Phone, order.Delivery.Zip,
-			order.Delivery.City, order.Delivery.Address, order.Delivery.Region, order.Delivery.Email,
-		).
-		WillReturnResult(pgxmock.NewResult("INSERT", 1))
-
-	// 3. Ожидаем INSERT в payment
-	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO payment`)).
-		WithArgs(
-			order.Payment.Transaction, order.Payment.RequestID, order.Payment.Currency, order.Payment.Provider,
-			order.Payment.Amount, order.Payment.PaymentDt, order.Payment.Bank, order.Payment.DeliveryCost,
-			order.Payment.GoodsTotal, order.Payment.CustomFee,
-		).
+		WithArgs(deliveryArgs(order)...).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
-	// 4. Ожидаем INSERT в orders
+	// 3. Ожидаем INSERT в orders, затем оплату
 	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO orders`)).
 		WithArgs(
 			order.OrderUID, order.TrackNumber, order.Entry, order.CustomerID, order.DeliveryService,
 			order.Shardkey, order.SmID, order.DateCreated, order.OofShard, order.Locale, order.InternalSignature,
+			mustPayloadHash(t, order),
 		).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	expectPaymentWrite(mock, order)
 
-	// 5. Ожидаем INSERT для каждого товара (в нашем случае 1)
+	// 4. Ожидаем INSERT для каждого товара (в нашем случае 1)
 	for _, item := range order.Items {
 		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO items`)).
 			WithArgs(
@@ -104,7 +177,7 @@ func TestPostgresStore_SaveOrder_Success(t *testing.T) {
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 	}
 
-	// 6. Ожидаем Commit транзакции
+	// 5. Ожидаем Commit транзакции
 	mock.ExpectCommit()
 
 	// Вызываем тестируемую функцию
@@ -123,15 +196,13 @@ func TestPostgresStore_SaveOrder_FailDelivery(t *testing.T) {
 	order := newTestOrderData("order-fail")
 	dbErr := errors.New("db error on delivery")
 
-	// 1. Ожидаем начала транзакции
+	// 1. Ожидаем начала транзакции и проверку существования заказа
 	mock.ExpectBegin()
+	expectOrderLookup(mock, order.OrderUID, nil)
 
 	// 2. Ожидаем INSERT в delivery, который вернет ошибку
 	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO delivery`)).
-		WithArgs(
-			order.OrderUID, order.Delivery.Name, order.Delivery.Phone, order.Delivery.Zip,
-			order.Delivery.City, order.Delivery.Address, order.Delivery.Region, order.Delivery.Email,
-		).
+		WithArgs(deliveryArgs(order)...).
 		WillReturnError(dbErr)
 
 	// 3. Ожидаем Rollback транзакции
@@ -154,26 +225,19 @@ func TestPostgresStore_SaveOrder_FailCommit(t *testing.T) {
 	commitErr := errors.New("commit error")
 
 	mock.ExpectBegin()
+	expectOrderLookup(mock, order.OrderUID, nil)
 	// (Все Exec'и проходят успешно)
 	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO delivery`)).
-		WithArgs(
-			order.OrderUID, order.Delivery.Name, order.Delivery.Phone, order.Delivery.Zip,
-			order.Delivery.City, order.Delivery.Address, order.Delivery.Region, order.Delivery.Email,
-		).
-		WillReturnResult(pgxmock.NewResult("INSERT", 1))
-	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO payment`)).
-		WithArgs(
-			order.Payment.Transaction, order.Payment.RequestID, order.Payment.Currency, order.Payment.Provider,
-			order.Payment.Amount, order.Payment.PaymentDt, order.Payment.Bank, order.Payment.DeliveryCost,
-			order.Payment.GoodsTotal, order.Payment.CustomFee,
-		).
+		WithArgs(deliveryArgs(order)...).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO orders`)).
 		WithArgs(
 			order.OrderUID, order.TrackNumber, order.Entry, order.CustomerID, order.DeliveryService,
 			order.Shardkey, order.SmID, order.DateCreated, order.OofShard, order.Locale, order.InternalSignature,
+			mustPayloadHash(t, order),
 		).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	expectPaymentWrite(mock, order)
 	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO items`)).
 		WithArgs(
 			order.OrderUID, order.Items[0].ChrtID, order.Items[0].TrackNumber, order.Items[0].Price, order.Items[0].Rid, order.Items[0].Name,
@@ -192,6 +256,218 @@ func TestPostgresStore_SaveOrder_FailCommit(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet(), "there were unfulfilled expectations")
 }
 
+// TestPostgresStore_SaveOrder_Duplicate проверяет, что повторная доставка того же заказа — no-op
+func TestPostgresStore_SaveOrder_Duplicate(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	order := newTestOrderData("order-duplicate")
+	hash := mustPayloadHash(t, order)
+
+	mock.ExpectBegin()
+	expectOrderLookup(mock, order.OrderUID, &hash)
+	// Никаких INSERT, сразу Commit
+	mock.ExpectCommit()
+
+	err := store.SaveOrder(ctx, order)
+	assert.NoError(t, err, "duplicate delivery must be a no-op")
+
+	assert.NoError(t, mock.ExpectationsWereMet(), "there were unfulfilled expectations")
+}
+
+// TestPostgresStore_SaveOrder_ConflictReject проверяет отказ при изменении заказа в режиме reject
+func TestPostgresStore_SaveOrder_ConflictReject(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	store.ConflictMode = ConflictReject
+	order := newTestOrderData("order-conflict")
+	oldHash := "other-hash"
+
+	mock.ExpectBegin()
+	expectOrderLookup(mock, order.OrderUID, &oldHash)
+	mock.ExpectRollback()
+
+	err := store.SaveOrder(ctx, order)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrOrderConflict)
+
+	assert.NoError(t, mock.ExpectationsWereMet(), "there were unfulfilled expectations")
+}
+
+// advisoryLock моделирует pg_advisory_xact_lock для pgxmock: блокировка общая для
+// всех пулов, держится до Commit или Rollback, а ее захваты и освобождения
+// записываются в events.
+type advisoryLock struct {
+	mu       sync.Mutex
+	eventsMu sync.Mutex
+	events   []string
+	acquired chan string
+}
+
+func (l *advisoryLock) record(event string) {
+	l.eventsMu.Lock()
+	defer l.eventsMu.Unlock()
+	l.events = append(l.events, event)
+}
+
+type advisoryPool struct {
+	pgxmock.PgxPoolIface
+	name string
+	lock *advisoryLock
+}
+
+func (p advisoryPool) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := p.PgxPoolIface.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &advisoryTx{Tx: tx, name: p.name, lock: p.lock}, nil
+}
+
+type advisoryTx struct {
+	pgx.Tx
+	name   string
+	lock   *advisoryLock
+	locked bool
+}
+
+func (tx *advisoryTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if strings.Contains(sql, "pg_advisory_xact_lock") {
+		tx.lock.mu.Lock()
+		tx.locked = true
+		tx.lock.record(tx.name + " lock")
+		tx.lock.acquired <- tx.name
+	}
+	return tx.Tx.Exec(ctx, sql, args...)
+}
+
+func (tx *advisoryTx) Commit(ctx context.Context) error {
+	defer tx.unlock()
+	return tx.Tx.Commit(ctx)
+}
+
+func (tx *advisoryTx) Rollback(ctx context.Context) error {
+	defer tx.unlock()
+	return tx.Tx.Rollback(ctx)
+}
+
+func (tx *advisoryTx) unlock() {
+	if tx.locked {
+		tx.locked = false
+		tx.lock.record(tx.name + " unlock")
+		tx.lock.mu.Unlock()
+	}
+}
+
+// TestPostgresStore_SaveOrder_ConcurrentFirstDelivery проверяет, что две одновременные
+// первые доставки одного заказа с разными данными не перезаписывают друг друга:
+// вторая ждет блокировку UID, пока первая не зафиксирует заказ, видит его и в режиме
+// reject получает ErrOrderConflict
+func TestPostgresStore_SaveOrder_ConcurrentFirstDelivery(t *testing.T) {
+	// --- Arrange ---
+	ctx := context.Background()
+	lock := &advisoryLock{acquired: make(chan string, 2)}
+	first := newTestOrderData("order-race")
+	second := first
+	second.Payment.Amount++
+	firstHash := mustPayloadHash(t, first)
+	opts := StoreOptions{ConflictMode: ConflictReject}
+
+	firstMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer firstMock.Close()
+	firstMock.ExpectBegin()
+	expectOrderLookup(firstMock, first.OrderUID, nil)
+	firstMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO delivery`)).WithArgs(deliveryArgs(first)...).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	firstMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO orders`)).WithArgs(anyArgs(12)...).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	expectPaymentWrite(firstMock, first)
+	for range first.Items {
+		firstMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO items`)).WithArgs(anyArgs(12)...).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+	firstMock.ExpectCommit().WillDelayFor(50 * time.Millisecond) // вторая доставка успевает дойти до блокировки
+
+	// Вторая транзакция видит заказ, зафиксированный первой
+	secondMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer secondMock.Close()
+	secondMock.ExpectBegin()
+	expectOrderLookup(secondMock, second.OrderUID, &firstHash)
+	secondMock.ExpectRollback()
+
+	firstStore := &PostgresStore{DB: advisoryPool{firstMock, "first", lock}, StoreOptions: opts}
+	secondStore := &PostgresStore{DB: advisoryPool{secondMock, "second", lock}, StoreOptions: opts}
+
+	// --- Act ---
+	var wg sync.WaitGroup
+	var firstErr, secondErr error
+	wg.Add(2)
+	go func() { defer wg.Done(); firstErr = firstStore.SaveOrder(ctx, first) }()
+	require.Equal(t, "first", <-lock.acquired)
+	go func() { defer wg.Done(); secondErr = secondStore.SaveOrder(ctx, second) }()
+	require.Equal(t, "second", <-lock.acquired)
+	wg.Wait()
+
+	// --- Assert ---
+	require.NoError(t, firstErr)
+	assert.ErrorIs(t, secondErr, ErrOrderConflict)
+	assert.Equal(t, []string{"first lock", "first unlock", "second lock", "second unlock"}, lock.events)
+	assert.NoError(t, firstMock.ExpectationsWereMet())
+	assert.NoError(t, secondMock.ExpectationsWereMet())
+}
+
+// TestPostgresStore_SaveOrder_Upsert проверяет перезапись измененного заказа с заменой товаров
+func TestPostgresStore_SaveOrder_Upsert(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	store.ConflictMode = ConflictUpsert
+	order := newTestOrderData("order-upsert")
+	oldHash := "other-hash"
+
+	mock.ExpectBegin()
+	expectOrderLookup(mock, order.OrderUID, &oldHash)
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO delivery`)).WithArgs(deliveryArgs(order)...).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO orders`)).WithArgs(
+		order.OrderUID, order.TrackNumber, order.Entry, order.CustomerID, order.DeliveryService,
+		order.Shardkey, order.SmID, order.DateCreated, order.OofShard, order.Locale, order.InternalSignature,
+		mustPayloadHash(t, order),
+	).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	expectPaymentWrite(mock, order)
+	// Старые товары удаляются перед вставкой новых
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM items WHERE order_uid = $1`)).
+		WithArgs(order.OrderUID).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+	for _, item := range order.Items {
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO items`)).WithArgs(
+			order.OrderUID, item.ChrtID, item.TrackNumber, item.Price, item.Rid, item.Name,
+			item.Sale, item.Size, item.TotalPrice, item.NmID, item.Brand, item.Status,
+		).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+	mock.ExpectCommit()
+
+	err := store.SaveOrder(ctx, order)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet(), "there were unfulfilled expectations")
+}
+
+// TestPayloadHash_IgnoresTimeZone проверяет, что хэш не зависит от часового пояса даты
+func TestPayloadHash_IgnoresTimeZone(t *testing.T) {
+	order := newTestOrderData("order-hash")
+	order.DateCreated = time.Date(2025, 10, 26, 8, 22, 19, 0, time.UTC)
+	moved := order
+	moved.DateCreated = order.DateCreated.In(time.FixedZone("MSK", 3*60*60))
+	changed := order
+	changed.TrackNumber = "OTHER"
+
+	assert.Equal(t, mustPayloadHash(t, order), mustPayloadHash(t, moved))
+	assert.NotEqual(t, mustPayloadHash(t, order), mustPayloadHash(t, changed))
+}
+
 // TestPostgresStore_GetOrderByUID_Success проверяет успешное получение заказа
 func TestPostgresStore_GetOrderByUID_Success(t *testing.T) {
 	ctx := context.Background()
@@ -215,12 +491,12 @@ func TestPostgresStore_GetOrderByUID_Success(t *testing.T) {
 
 	// 2. Ожидаем запрос в 'delivery'
 	deliveryRows := pgxmock.NewRows([]string{
-		"name", "phone", "zip", "city", "address", "region", "email",
+		"name", "phone", "zip", "city", "address", "region", "email", "country",
 	}).AddRow(
 		order.Delivery.Name, order.Delivery.Phone, order.Delivery.Zip, order.Delivery.City,
-		order.Delivery.Address, order.Delivery.Region, order.Delivery.Email,
+		order.Delivery.Address, order.Delivery.Region, order.Delivery.Email, order.Delivery.Country,
 	)
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT name, phone, zip, city, address, region, email FROM delivery WHERE order_uid = $1`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT name, phone, zip, city, address, region, email, country FROM delivery WHERE order_uid = $1`)).
 		WithArgs(uid).
 		WillReturnRows(deliveryRows)
 
@@ -233,7 +509,7 @@ func TestPostgresStore_GetOrderByUID_Success(t *testing.T) {
 		order.Payment.Amount, order.Payment.PaymentDt, order.Payment.Bank, order.Payment.DeliveryCost,
 		order.Payment.GoodsTotal, order.Payment.CustomFee,
 	)
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT transaction_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee FROM payment WHERE transaction_id = $1`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT transaction_id, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee FROM payment WHERE order_uid = $1 AND is_current`)).
 		WithArgs(uid).
 		WillReturnRows(paymentRows)
 
@@ -282,33 +558,283 @@ func TestPostgresStore_GetOrderByUID_OrderNotFound(t *testing.T) {
 
 	// Проверяем, что функция вернула обернутую ошибку pgx.ErrNoRows
 	assert.ErrorIs(t, err, pgx.ErrNoRows, "expected error to wrap pgx.ErrNoRows")
+	assert.ErrorIs(t, err, ErrNotFound, "expected error to be ErrNotFound")
 	assert.Contains(t, err.Error(), fmt.Sprintf("заказ с UID %s не найден", uid), "wrong error message")
 
 	assert.NoError(t, mock.ExpectationsWereMet(), "there were unfulfilled expectations")
 }
 
-// TestPostgresStore_GetRecentOrderUIDs_Success проверяет успешное получение UID'ов
+// joinedOrderRows возвращает строки результата ordersByUIDsQuery для заказов.
+func joinedOrderRows(t *testing.T, orders ...model.OrderData) *pgxmock.Rows {
+	t.Helper()
+	rows := pgxmock.NewRows([]string{
+		"order_uid", "track_number", "entry", "customer_id", "delivery_service", "shardkey", "sm_id",
+		"date_created", "oof_shard", "locale", "internal_signature",
+		"name", "phone", "zip", "city", "address", "region", "email", "country",
+		"transaction_id", "request_id", "currency", "provider", "amount", "payment_dt", "bank",
+		"delivery_cost", "goods_total", "custom_fee",
+		"items",
+	})
+	for _, o := range orders {
+		items, err := json.Marshal(o.Items)
+		require.NoError(t, err)
+		rows.AddRow(
+			o.OrderUID, o.TrackNumber, o.Entry, o.CustomerID, o.DeliveryService, o.Shardkey, o.SmID,
+			o.DateCreated, o.OofShard, o.Locale, o.InternalSignature,
+			o.Delivery.Name, o.Delivery.Phone, o.Delivery.Zip, o.Delivery.City,
+			o.Delivery.Address, o.Delivery.Region, o.Delivery.Email, o.Delivery.Country,
+			o.Payment.Transaction, o.Payment.RequestID, o.Payment.Currency, o.Payment.Provider,
+			o.Payment.Amount, o.Payment.PaymentDt, o.Payment.Bank, o.Payment.DeliveryCost,
+			o.Payment.GoodsTotal, o.Payment.CustomFee,
+			items,
+		)
+	}
+	return rows
+}
+
+// TestPostgresStore_GetOrderByUID_Joined проверяет загрузку заказа одним запросом
+func TestPostgresStore_GetOrderByUID_Joined(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	store.LoadStrategy = LoadJoined
+	uid := "order-get-joined"
+	order := newTestOrderData(uid)
+
+	mock.ExpectQuery(`FROM orders o JOIN delivery d .* WHERE o.order_uid = ANY\(\$1\)`).
+		WithArgs([]string{uid}).
+		WillReturnRows(joinedOrderRows(t, order))
+
+	retrievedOrder, err := store.GetOrderByUID(ctx, uid)
+	require.NoError(t, err)
+
+	assert.Equal(t, order.OrderUID, retrievedOrder.OrderUID)
+	assert.Equal(t, order.Delivery, retrievedOrder.Delivery)
+	assert.Equal(t, order.Payment, retrievedOrder.Payment)
+	assert.Equal(t, order.Items, retrievedOrder.Items)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresStore_GetOrderByUID_JoinedNotFound проверяет ошибку "не найдено" при загрузке одним запросом
+func TestPostgresStore_GetOrderByUID_JoinedNotFound(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	store.LoadStrategy = LoadJoined
+	uid := "order-not-found"
+
+	mock.ExpectQuery(`FROM orders o JOIN delivery d`).
+		WithArgs([]string{uid}).
+		WillReturnRows(joinedOrderRows(t))
+
+	retrievedOrder, err := store.GetOrderByUID(ctx, uid)
+
+	assert.Nil(t, retrievedOrder)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresStore_GetOrderByUID_Unavailable проверяет, что сбой соединения отличается от "не найдено"
+func TestPostgresStore_GetOrderByUID_Unavailable(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	uid := "order-db-down"
+
+	mock.ExpectQuery(`FROM orders WHERE order_uid = \$1`).
+		WithArgs(uid).
+		WillReturnError(&pgconn.PgError{Code: "57P03"})
+
+	retrievedOrder, err := store.GetOrderByUID(ctx, uid)
+
+	assert.Nil(t, retrievedOrder)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.NotErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresStore_GetOrderByUID_ItemsRowsError проверяет, что обрыв чтения
+// товаров не возвращает заказ с неполным списком Items
+func TestPostgresStore_GetOrderByUID_ItemsRowsError(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	uid := "order-items-broken"
+	order := newTestOrderData(uid)
+	item := order.Items[0]
+
+	mock.ExpectQuery(`FROM orders WHERE order_uid = \$1`).
+		WithArgs(uid).
+		WillReturnRows(pgxmock.NewRows([]string{
+			"order_uid", "track_number", "entry", "customer_id", "delivery_service",
+			"shardkey", "sm_id", "date_created", "oof_shard", "locale", "internal_signature",
+		}).AddRow(
+			order.OrderUID, order.TrackNumber, order.Entry, order.CustomerID, order.DeliveryService,
+			order.Shardkey, order.SmID, order.DateCreated, order.OofShard, order.Locale, order.InternalSignature,
+		))
+	mock.ExpectQuery(`FROM delivery WHERE order_uid = \$1`).
+		WithArgs(uid).
+		WillReturnRows(pgxmock.NewRows([]string{
+			"name", "phone", "zip", "city", "address", "region", "email", "country",
+		}).AddRow(
+			order.Delivery.Name, order.Delivery.Phone, order.Delivery.Zip, order.Delivery.City,
+			order.Delivery.Address, order.Delivery.Region, order.Delivery.Email, order.Delivery.Country,
+		))
+	mock.ExpectQuery(`FROM payment WHERE order_uid = \$1 AND is_current`).
+		WithArgs(uid).
+		WillReturnRows(pgxmock.NewRows([]string{
+			"transaction_id", "request_id", "currency", "provider", "amount",
+			"payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee",
+		}).AddRow(
+			order.Payment.Transaction, order.Payment.RequestID, order.Payment.Currency, order.Payment.Provider,
+			order.Payment.Amount, order.Payment.PaymentDt, order.Payment.Bank, order.Payment.DeliveryCost,
+			order.Payment.GoodsTotal, order.Payment.CustomFee,
+		))
+	// Первая строка прочитана, после нее соединение оборвалось: ошибку вернет rows.Err()
+	itemsRows := pgxmock.NewRows([]string{
+		"chrt_id", "track_number", "price", "rid", "name", "sale",
+		"size", "total_price", "nm_id", "brand", "status",
+	}).AddRow(
+		item.ChrtID, item.TrackNumber, item.Price, item.Rid, item.Name, item.Sale,
+		item.Size, item.TotalPrice, item.NmID, item.Brand, item.Status,
+	).RowError(1, &pgconn.PgError{Code: "57P01"})
+	mock.ExpectQuery(`FROM items WHERE order_uid = \$1`).
+		WithArgs(uid).
+		WillReturnRows(itemsRows)
+
+	retrievedOrder, err := store.GetOrderByUID(ctx, uid)
+
+	assert.Nil(t, retrievedOrder, "order with truncated items must not be returned")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Contains(t, err.Error(), fmt.Sprintf("ошибка при чтении товаров заказа %s", uid))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresStore_GetOrderByTrackNumber проверяет поиск заказа по трек-номеру
+func TestPostgresStore_GetOrderByTrackNumber(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	store.LoadStrategy = LoadJoined
+	order := newTestOrderData("order-by-track")
+
+	// 1. Находим UID самого нового заказа с этим трек-номером
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT order_uid FROM orders WHERE track_number = $1 ORDER BY date_created DESC`)).
+		WithArgs(order.TrackNumber).
+		WillReturnRows(pgxmock.NewRows([]string{"order_uid"}).AddRow(order.OrderUID))
+	// 2. Загружаем заказ целиком
+	mock.ExpectQuery(`FROM orders o JOIN delivery d`).
+		WithArgs([]string{order.OrderUID}).
+		WillReturnRows(joinedOrderRows(t, order))
+
+	retrievedOrder, err := store.GetOrderByTrackNumber(ctx, order.TrackNumber)
+	require.NoError(t, err)
+
+	assert.Equal(t, order.OrderUID, retrievedOrder.OrderUID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresStore_GetOrderByTrackNumber_NotFound проверяет ошибку "не найдено"
+func TestPostgresStore_GetOrderByTrackNumber_NotFound(t *testing.T) {
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+
+	mock.ExpectQuery(`FROM orders WHERE track_number = \$1`).
+		WithArgs("UNKNOWN").
+		WillReturnError(pgx.ErrNoRows)
+
+	retrievedOrder, err := store.GetOrderByTrackNumber(context.Background(), "UNKNOWN")
+
+	assert.Nil(t, retrievedOrder)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresStore_GetOrdersByUIDs проверяет порядок результата и пропуск отсутствующих заказов
+func TestPostgresStore_GetOrdersByUIDs(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	first := newTestOrderData("order-bulk-1")
+	second := newTestOrderData("order-bulk-2")
+	second.Items = nil
+	uids := []string{"order-bulk-1", "order-bulk-missing", "order-bulk-2"}
+
+	// БД возвращает строки в произвольном порядке
+	mock.ExpectQuery(`FROM orders o JOIN delivery d`).
+		WithArgs(uids).
+		WillReturnRows(joinedOrderRows(t, second, first))
+
+	orders, err := store.GetOrdersByUIDs(ctx, uids)
+	require.NoError(t, err)
+
+	require.Len(t, orders, 2)
+	assert.Equal(t, "order-bulk-1", orders[0].OrderUID)
+	assert.Equal(t, "order-bulk-2", orders[1].OrderUID)
+	assert.Len(t, orders[0].Items, len(first.Items))
+	assert.Nil(t, orders[1].Items, "заказ без товаров должен загружаться как при отдельных запросах")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresStore_GetOrdersByUIDs_Empty проверяет, что пустой список не обращается к БД
+func TestPostgresStore_GetOrdersByUIDs_Empty(t *testing.T) {
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+
+	orders, err := store.GetOrdersByUIDs(context.Background(), nil)
+
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresStore_GetRecentOrderUIDs_Success проверяет получение первой страницы ключей
 func TestPostgresStore_GetRecentOrderUIDs_Success(t *testing.T) {
 	ctx := context.Background()
 	store, mock := newMockPostgresStore(t)
 	defer mock.Close()
 	since := time.Now().Add(-1 * time.Hour).Truncate(time.Second)
+	created := since.Add(time.Minute)
 
 	// 1. Ожидаем запрос
-	rows := pgxmock.NewRows([]string{"order_uid"}).
-		AddRow("uid-1").
-		AddRow("uid-2").
-		AddRow("uid-3")
+	rows := pgxmock.NewRows([]string{"order_uid", "date_created"}).
+		AddRow("uid-1", created).
+		AddRow("uid-2", created).
+		AddRow("uid-3", since)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT order_uid FROM orders WHERE date_created >= $1`)).
-		WithArgs(since).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT order_uid, date_created FROM orders WHERE date_created >= $1 ORDER BY date_created DESC, order_uid DESC LIMIT $2`)).
+		WithArgs(since, 3).
 		WillReturnRows(rows)
 
 	// Вызываем функцию
-	uids, err := store.GetRecentOrderUIDs(ctx, since)
+	keys, err := store.GetRecentOrderUIDs(ctx, since, nil, 3)
 	require.NoError(t, err, "error was not expected while getting recent UIDs")
-	require.Len(t, uids, 3, "expected 3 UIDs")
-	assert.Equal(t, []string{"uid-1", "uid-2", "uid-3"}, uids, "wrong UIDs returned")
+	assert.Equal(t, []OrderKey{
+		{UID: "uid-1", DateCreated: created},
+		{UID: "uid-2", DateCreated: created},
+		{UID: "uid-3", DateCreated: since},
+	}, keys, "wrong keys returned")
+
+	assert.NoError(t, mock.ExpectationsWereMet(), "there were unfulfilled expectations")
+}
+
+// TestPostgresStore_GetRecentOrderUIDs_After проверяет, что следующая страница
+// читается по ключу последнего заказа предыдущей
+func TestPostgresStore_GetRecentOrderUIDs_After(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	since := time.Now().Add(-1 * time.Hour).Truncate(time.Second)
+	after := OrderKey{UID: "uid-3", DateCreated: since}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT order_uid, date_created FROM orders WHERE date_created >= $1 AND (date_created, order_uid) < ($2, $3) ORDER BY date_created DESC, order_uid DESC LIMIT $4`)).
+		WithArgs(since, after.DateCreated, after.UID, 3).
+		WillReturnRows(pgxmock.NewRows([]string{"order_uid", "date_created"}))
+
+	keys, err := store.GetRecentOrderUIDs(ctx, since, &after, 3)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
 
 	assert.NoError(t, mock.ExpectationsWereMet(), "there were unfulfilled expectations")
 }
@@ -322,15 +848,131 @@ func TestPostgresStore_GetRecentOrderUIDs_QueryFail(t *testing.T) {
 	dbErr := errors.New("query failed")
 
 	// 1. Ожидаем запрос, который вернет ошибку
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT order_uid FROM orders WHERE date_created >= $1`)).
-		WithArgs(since).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT order_uid, date_created FROM orders WHERE date_created >= $1 ORDER BY date_created DESC, order_uid DESC LIMIT $2`)).
+		WithArgs(since, 500).
 		WillReturnError(dbErr)
 
 	// Вызываем функцию
-	uids, err := store.GetRecentOrderUIDs(ctx, since)
+	keys, err := store.GetRecentOrderUIDs(ctx, since, nil, 500)
 	require.Error(t, err, "expected an error, but got nil")
-	assert.Nil(t, uids, "UIDs should be nil on error")
+	assert.Nil(t, keys, "keys should be nil on error")
 	assert.ErrorIs(t, err, dbErr, "expected error to wrap the db error")
 
 	assert.NoError(t, mock.ExpectationsWereMet(), "there were unfulfilled expectations")
 }
+
+// TestPostgresStore_SaveOrders_Copy проверяет сохранение новых заказов через COPY
+func TestPostgresStore_SaveOrders_Copy(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	orders := []model.OrderData{newTestOrderData("batch-1"), newTestOrderData("batch-2")}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT order_uid, payload_hash FROM orders WHERE order_uid = ANY($1) FOR UPDATE`)).
+		WithArgs([]string{"batch-1", "batch-2"}).
+		WillReturnRows(pgxmock.NewRows([]string{"order_uid", "payload_hash"}))
+	mock.ExpectCopyFrom(pgx.Identifier{"delivery"}, deliveryCopyColumns).WillReturnResult(2)
+	mock.ExpectCopyFrom(pgx.Identifier{"orders"}, ordersCopyColumns).WillReturnResult(2)
+	mock.ExpectCopyFrom(pgx.Identifier{"payment"}, paymentCopyColumns).WillReturnResult(2)
+	mock.ExpectCopyFrom(pgx.Identifier{"items"}, itemsCopyColumns).WillReturnResult(2)
+	mock.ExpectCommit()
+
+	errs, err := store.SaveOrders(ctx, orders)
+	require.NoError(t, err)
+	assert.Equal(t, []error{nil, nil}, errs)
+
+	assert.NoError(t, mock.ExpectationsWereMet(), "there were unfulfilled expectations")
+}
+
+// TestPostgresStore_SaveOrders_ExistingOrders проверяет дубликаты и конфликты внутри пачки
+func TestPostgresStore_SaveOrders_ExistingOrders(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	store.ConflictMode = ConflictReject
+	duplicate := newTestOrderData("batch-dup")
+	conflict := newTestOrderData("batch-conflict")
+	fresh := newTestOrderData("batch-new")
+	dupHash := mustPayloadHash(t, duplicate)
+	oldHash := "other-hash"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT order_uid, payload_hash FROM orders WHERE order_uid = ANY($1) FOR UPDATE`)).
+		WithArgs([]string{"batch-dup", "batch-conflict", "batch-new"}).
+		WillReturnRows(pgxmock.NewRows([]string{"order_uid", "payload_hash"}).
+			AddRow("batch-dup", &dupHash).
+			AddRow("batch-conflict", &oldHash))
+	// Копируется только новый заказ
+	mock.ExpectCopyFrom(pgx.Identifier{"delivery"}, deliveryCopyColumns).WillReturnResult(1)
+	mock.ExpectCopyFrom(pgx.Identifier{"orders"}, ordersCopyColumns).WillReturnResult(1)
+	mock.ExpectCopyFrom(pgx.Identifier{"payment"}, paymentCopyColumns).WillReturnResult(1)
+	mock.ExpectCopyFrom(pgx.Identifier{"items"}, itemsCopyColumns).WillReturnResult(1)
+	mock.ExpectCommit()
+
+	errs, err := store.SaveOrders(ctx, []model.OrderData{duplicate, conflict, fresh})
+	require.NoError(t, err)
+	require.Len(t, errs, 3)
+	assert.NoError(t, errs[0], "duplicate must be a no-op")
+	assert.ErrorIs(t, errs[1], ErrOrderConflict)
+	assert.NoError(t, errs[2])
+
+	assert.NoError(t, mock.ExpectationsWereMet(), "there were unfulfilled expectations")
+}
+
+// TestPostgresStore_SaveOrders_FallbackPerOrder проверяет, что при ошибке данных
+// заказы сохраняются по одному и ошибка относится к конкретному заказу
+func TestPostgresStore_SaveOrders_FallbackPerOrder(t *testing.T) {
+	ctx := context.Background()
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	good := newTestOrderData("batch-good")
+	bad := newTestOrderData("batch-bad")
+	dataErr := errors.New("value too long")
+
+	// 1. Пачка целиком не проходит
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT order_uid, payload_hash FROM orders WHERE order_uid = ANY($1) FOR UPDATE`)).
+		WithArgs([]string{"batch-good", "batch-bad"}).
+		WillReturnRows(pgxmock.NewRows([]string{"order_uid", "payload_hash"}))
+	mock.ExpectCopyFrom(pgx.Identifier{"delivery"}, deliveryCopyColumns).WillReturnError(dataErr)
+	mock.ExpectRollback()
+
+	// 2. Первый заказ сохраняется отдельно успешно
+	mock.ExpectBegin()
+	expectOrderLookup(mock, good.OrderUID, nil)
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO delivery`)).WithArgs(anyArgs(10)...).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO orders`)).WithArgs(anyArgs(12)...).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE payment SET is_current = false`)).WithArgs(anyArgs(2)...).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO payment`)).WithArgs(anyArgs(11)...).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO items`)).WithArgs(anyArgs(12)...).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	// 3. Второй — с ошибкой
+	mock.ExpectBegin()
+	expectOrderLookup(mock, bad.OrderUID, nil)
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO delivery`)).WithArgs(anyArgs(10)...).WillReturnError(dataErr)
+	mock.ExpectRollback()
+
+	errs, err := store.SaveOrders(ctx, []model.OrderData{good, bad})
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], dataErr)
+
+	assert.NoError(t, mock.ExpectationsWereMet(), "there were unfulfilled expectations")
+}
+
+// TestPostgresStore_Ping проверяет проверку соединения с БД
+func TestPostgresStore_Ping(t *testing.T) {
+	store, mock := newMockPostgresStore(t)
+	defer mock.Close()
+	pingErr := errors.New("connection refused")
+
+	mock.ExpectPing()
+	mock.ExpectPing().WillReturnError(pingErr)
+
+	assert.NoError(t, store.Ping(context.Background()))
+	assert.ErrorIs(t, store.Ping(context.Background()), pingErr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
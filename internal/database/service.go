@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"l1/internal/metrics"
 	"l1/internal/model"
 )
 
@@ -14,8 +15,14 @@ import (
 // OrderDB определяет контракт для работы *только* с базой данных
 type OrderDB interface {
 	SaveOrder(ctx context.Context, order model.OrderData) error
+	SaveOrders(ctx context.Context, orders []model.OrderData) ([]error, error)
 	GetOrderByUID(ctx context.Context, orderUID string) (*model.OrderData, error)
-	GetRecentOrderUIDs(ctx context.Context, since time.Time) ([]string, error)
+	GetOrdersByUIDs(ctx context.Context, uids []string) ([]*model.OrderData, error)
+	GetRecentOrderUIDs(ctx context.Context, since time.Time, after *OrderKey, limit int) ([]OrderKey, error)
+	ListOrders(ctx context.Context, q ListOrdersQuery) (*OrderPage, error)
+	GetOrderByTrackNumber(ctx context.Context, track string) (*model.OrderData, error)
+	GetCustomerOrders(ctx context.Context, customerID string, q ListOrdersQuery) (*OrderPage, error)
+	GetPaymentAttempts(ctx context.Context, orderUID string) ([]PaymentAttempt, error)
 	Close()
 }
 
@@ -27,62 +34,47 @@ type OrderCache interface {
 	Count() int
 }
 
+// TrackIndex — необязательное расширение OrderCache для кэшей с индексом по трек-номеру
+type TrackIndex interface {
+	GetByTrackNumber(track string) (*model.OrderData, bool)
+}
+
+// CacheStatsProvider — необязательное расширение OrderCache для кэшей, собирающих статистику
+type CacheStatsProvider interface {
+	Stats() CacheStats
+}
+
 // --- 2. Сервис-Оркестратор ---
 
 // Service — это фасад, который управляет взаимодействием между БД и кэшем
 // Клиенты (например, HTTP хендлеры) работают только с ним.
 type Service struct {
-	db    OrderDB
-	cache OrderCache
+	db     OrderDB
+	cache  OrderCache
+	events *Broadcaster
 }
 
 // NewService — конструктор, использующий Dependency Injection
 func NewService(db OrderDB, cache OrderCache) *Service {
 	return &Service{
-		db:    db,
-		cache: cache,
+		db:     db,
+		cache:  cache,
+		events: NewBroadcaster(),
 	}
 }
 
 // RunBackgroundJobs запускает фоновые процессы, такие как прогрев кэша.
-func (s *Service) RunBackgroundJobs(ctx context.Context) {
+func (s *Service) RunBackgroundJobs(ctx context.Context, warmUp WarmUpOptions) {
 	go func() {
 		log.Println("Запуск фонового прогрева кэша...")
-		// Прогреваем данные за последние 7 дней
-		since := time.Now().Add(-7 * 24 * time.Hour)
-		if err := s.warmUpCache(ctx, since); err != nil {
-			log.Printf("Ошибка фонового прогрева кэша: %v", err)
-		} else {
-			log.Printf("Фоновый прогрев кэша завершён: %d заказов", s.cache.Count())
-		}
+		s.runWarmUp(ctx, warmUp)
 	}()
 }
 
-// warmUpCache выполняет прогрев кэша при старте
-func (s *Service) warmUpCache(ctx context.Context, since time.Time) error {
-	uids, err := s.db.GetRecentOrderUIDs(ctx, since)
-	if err != nil {
-		return fmt.Errorf("не удалось получить список заказов: %w", err)
-	}
-
-	count := 0
-	for _, uid := range uids {
-		// Идем напрямую в БД, чтобы загрузить данные
-		order, err := s.db.GetOrderByUID(ctx, uid)
-		if err != nil {
-			log.Printf("Пропускаем заказ %s при прогреве: %v", uid, err)
-			continue
-		}
-		// Напрямую кладем в кэш
-		s.cache.Set(uid, order)
-		count++
-	}
-	log.Printf("Прогрузили в кэш %d заказов", count)
-	return nil
-}
-
 // SaveOrder реализует паттерн "Write-Through Cache"
 func (s *Service) SaveOrder(ctx context.Context, order model.OrderData) error {
+	defer metrics.ObserveSince(metrics.ServiceDuration.With("SaveOrder", metrics.SourceDB), time.Now())
+
 	// 1. Сначала в постоянное хранилище (БД)
 	if err := s.db.SaveOrder(ctx, order); err != nil {
 		return fmt.Errorf("ошибка сохранения заказа в БД: %w", err)
@@ -91,20 +83,62 @@ func (s *Service) SaveOrder(ctx context.Context, order model.OrderData) error {
 	// 2. Затем обновляем кэш
 	log.Printf("Заказ %s сохранен в БД, обновляем кэш...", order.OrderUID)
 	s.cache.Set(order.OrderUID, &order)
+	s.events.Publish(OrderEvent{Order: &order, SavedAt: time.Now()})
 
 	return nil
 }
 
-// GetOrderByUID реализует паттерн "Cache-Aside"
+// SaveOrders сохраняет пачку заказов и обновляет кэш для успешно сохраненных.
+// Возвращает ошибки по каждому заказу и общую ошибку, если пачка не сохранена целиком.
+func (s *Service) SaveOrders(ctx context.Context, orders []model.OrderData) ([]error, error) {
+	defer metrics.ObserveSince(metrics.ServiceDuration.With("SaveOrders", metrics.SourceDB), time.Now())
+
+	errs, err := s.db.SaveOrders(ctx, orders)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сохранения пачки заказов в БД: %w", err)
+	}
+
+	saved := 0
+	for i := range orders {
+		if errs[i] != nil {
+			errs[i] = fmt.Errorf("ошибка сохранения заказа в БД: %w", errs[i])
+			continue
+		}
+		order := orders[i]
+		s.cache.Set(order.OrderUID, &order)
+		s.events.Publish(OrderEvent{Order: &order, SavedAt: time.Now()})
+		saved++
+	}
+	log.Printf("Пачка заказов сохранена в БД: %d из %d, кэш обновлен", saved, len(orders))
+
+	return errs, nil
+}
+
+// Subscribe подписывает на события о сохранении заказов. buffer — сколько событий
+// может ждать чтения; при переполнении новые события для подписки пропускаются.
+// Подписку нужно закрыть вызовом Close.
+func (s *Service) Subscribe(buffer int) *Subscription {
+	return s.events.Subscribe(buffer)
+}
+
+// GetOrderByUID реализует паттерн "Cache-Aside".
+// Ошибки проверяются через errors.Is: ErrInvalidUID, ErrNotFound, ErrUnavailable.
 func (s *Service) GetOrderByUID(ctx context.Context, orderUID string) (*model.OrderData, error) {
+	if err := ValidateUID(orderUID); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
 	// 1. Пытаемся прочитать из кэша
 	if order, ok := s.cache.Get(orderUID); ok {
 		log.Printf("Заказ %s найден в кэше", orderUID)
+		metrics.ObserveSince(metrics.ServiceDuration.With("GetOrderByUID", metrics.SourceCache), start)
 		return order, nil
 	}
 
 	// 2. Если в кэше нет — читаем из базы
 	log.Printf("Заказ %s не найден в кэше, обращаемся к БД...", orderUID)
+	defer metrics.ObserveSince(metrics.ServiceDuration.With("GetOrderByUID", metrics.SourceDB), start)
 	order, err := s.db.GetOrderByUID(ctx, orderUID)
 	if err != nil {
 		return nil, err // Ошибка (включая "не найдено")
@@ -116,19 +150,80 @@ func (s *Service) GetOrderByUID(ctx context.Context, orderUID string) (*model.Or
 	return order, nil
 }
 
+// GetOrderByTrackNumber ищет заказ по трек-номеру: сначала в кэше, если он поддерживает
+// индекс по трек-номеру, затем в БД. Найденный в БД заказ кладется в кэш.
+func (s *Service) GetOrderByTrackNumber(ctx context.Context, track string) (*model.OrderData, error) {
+	if err := ValidateTrackNumber(track); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	if index, ok := s.cache.(TrackIndex); ok {
+		if order, ok := index.GetByTrackNumber(track); ok {
+			metrics.ObserveSince(metrics.ServiceDuration.With("GetOrderByTrackNumber", metrics.SourceCache), start)
+			return order, nil
+		}
+	}
+
+	defer metrics.ObserveSince(metrics.ServiceDuration.With("GetOrderByTrackNumber", metrics.SourceDB), start)
+	order, err := s.db.GetOrderByTrackNumber(ctx, track)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(order.OrderUID, order)
+	return order, nil
+}
+
+// GetCustomerOrders возвращает страницу заказов покупателя из БД.
+func (s *Service) GetCustomerOrders(ctx context.Context, customerID string, q ListOrdersQuery) (*OrderPage, error) {
+	if err := ValidateCustomerID(customerID); err != nil {
+		return nil, err
+	}
+	defer metrics.ObserveSince(metrics.ServiceDuration.With("GetCustomerOrders", metrics.SourceDB), time.Now())
+	return s.db.GetCustomerOrders(ctx, customerID, q)
+}
+
+// GetPaymentAttempts возвращает историю попыток оплаты заказа из БД.
+func (s *Service) GetPaymentAttempts(ctx context.Context, orderUID string) ([]PaymentAttempt, error) {
+	if err := ValidateUID(orderUID); err != nil {
+		return nil, err
+	}
+	defer metrics.ObserveSince(metrics.ServiceDuration.With("GetPaymentAttempts", metrics.SourceDB), time.Now())
+	return s.db.GetPaymentAttempts(ctx, orderUID)
+}
+
+// ListOrders ищет заказы по фильтру. Поиск всегда идет в БД, кэш не используется.
+func (s *Service) ListOrders(ctx context.Context, q ListOrdersQuery) (*OrderPage, error) {
+	defer metrics.ObserveSince(metrics.ServiceDuration.With("ListOrders", metrics.SourceDB), time.Now())
+	return s.db.ListOrders(ctx, q)
+}
+
+// CacheStats возвращает статистику кэша. Второе значение false, если кэш ее не собирает.
+func (s *Service) CacheStats() (CacheStats, bool) {
+	provider, ok := s.cache.(CacheStatsProvider)
+	if !ok {
+		return CacheStats{}, false
+	}
+	return provider.Stats(), true
+}
+
 // InvalidateOrder — метод для инвалидации кэша
 func (s *Service) InvalidateOrder(uid string) {
 	log.Printf("Инвалидация кэша для заказа %s", uid)
 	s.cache.Delete(uid)
 }
 
-// Close закрывает пулы соединений
+// Close останавливает кэш и закрывает пулы соединений
 func (s *Service) Close() {
-	if s.db != nil {
-		s.db.Close()
-	}
+	// Подписчики на события завершаются до закрытия хранилищ
+	s.events.Close()
 
+	// Сначала кэш: его фоновые задачи не должны пережить соединение с БД
 	if closer, ok := s.cache.(interface{ Close() }); ok {
 		closer.Close()
 	}
+
+	if s.db != nil {
+		s.db.Close()
+	}
 }
@@ -3,9 +3,11 @@ package database
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"l1/internal/health"
 	"l1/internal/model"
 
 	"github.com/stretchr/testify/assert"
@@ -27,6 +29,14 @@ func (m *MockDB) SaveOrder(ctx context.Context, order model.OrderData) error {
 	return args.Error(0)
 }
 
+func (m *MockDB) SaveOrders(ctx context.Context, orders []model.OrderData) ([]error, error) {
+	args := m.Called(ctx, orders)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]error), args.Error(1)
+}
+
 func (m *MockDB) GetOrderByUID(ctx context.Context, orderUID string) (*model.OrderData, error) {
 	args := m.Called(ctx, orderUID)
 	if args.Get(0) == nil {
@@ -35,12 +45,61 @@ func (m *MockDB) GetOrderByUID(ctx context.Context, orderUID string) (*model.Ord
 	return args.Get(0).(*model.OrderData), args.Error(1)
 }
 
-func (m *MockDB) GetRecentOrderUIDs(ctx context.Context, since time.Time) ([]string, error) {
-	args := m.Called(ctx, since)
+func (m *MockDB) GetOrdersByUIDs(ctx context.Context, uids []string) ([]*model.OrderData, error) {
+	args := m.Called(ctx, uids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.OrderData), args.Error(1)
+}
+
+func (m *MockDB) ListOrders(ctx context.Context, q ListOrdersQuery) (*OrderPage, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OrderPage), args.Error(1)
+}
+
+func (m *MockDB) GetOrderByTrackNumber(ctx context.Context, track string) (*model.OrderData, error) {
+	args := m.Called(ctx, track)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.OrderData), args.Error(1)
+}
+
+func (m *MockDB) GetCustomerOrders(ctx context.Context, customerID string, q ListOrdersQuery) (*OrderPage, error) {
+	args := m.Called(ctx, customerID, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*OrderPage), args.Error(1)
+}
+
+func (m *MockDB) GetPaymentAttempts(ctx context.Context, orderUID string) ([]PaymentAttempt, error) {
+	args := m.Called(ctx, orderUID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]string), args.Error(1)
+	return args.Get(0).([]PaymentAttempt), args.Error(1)
+}
+
+func (m *MockDB) GetRecentOrderUIDs(ctx context.Context, since time.Time, after *OrderKey, limit int) ([]OrderKey, error) {
+	args := m.Called(ctx, since, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]OrderKey), args.Error(1)
+}
+
+// orderKeys строит ключи прогрева для uids.
+func orderKeys(uids ...string) []OrderKey {
+	keys := make([]OrderKey, len(uids))
+	for i, uid := range uids {
+		keys[i] = OrderKey{UID: uid}
+	}
+	return keys
 }
 
 func (m *MockDB) Close() {
@@ -164,6 +223,49 @@ func TestService_GetOrderByUID_CacheMissAndDBError(t *testing.T) {
 	mockCache.AssertExpectations(t)
 }
 
+// TestService_GetOrderByUID_InvalidUID проверяет, что некорректный UID не доходит до кэша и БД
+func TestService_GetOrderByUID_InvalidUID(t *testing.T) {
+	// --- Arrange ---
+	mockDB := new(MockDB)
+	mockCache := new(MockCache)
+	service := NewService(mockDB, mockCache)
+
+	// --- Act ---
+	order, err := service.GetOrderByUID(context.Background(), "bad uid")
+
+	// --- Assert ---
+	assert.Nil(t, order)
+	assert.ErrorIs(t, err, ErrInvalidUID)
+	mockCache.AssertNotCalled(t, "Get", mock.Anything)
+	mockDB.AssertNotCalled(t, "GetOrderByUID", mock.Anything, mock.Anything)
+}
+
+// TestService_GetOrderByTrackNumber проверяет (не найдено в индексе кэша -> БД -> кэш -> индекс кэша)
+func TestService_GetOrderByTrackNumber(t *testing.T) {
+	// --- Arrange ---
+	mockDB := new(MockDB)
+	cache := NewMemoryCache(time.Minute)
+	defer cache.Close()
+	service := NewService(mockDB, cache)
+	order := &model.OrderData{OrderUID: "test-uid", TrackNumber: "TRACK-1"}
+
+	// БД вызывается только при первом обращении
+	mockDB.On("GetOrderByTrackNumber", mock.Anything, "TRACK-1").Return(order, nil).Once()
+
+	// --- Act ---
+	first, err1 := service.GetOrderByTrackNumber(context.Background(), "TRACK-1")
+	second, err2 := service.GetOrderByTrackNumber(context.Background(), "TRACK-1")
+
+	// --- Assert ---
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	assert.Equal(t, order, first)
+	assert.Equal(t, order, second)
+	_, ok := cache.Get("test-uid")
+	assert.True(t, ok, "заказ должен попасть в кэш и под своим UID")
+	mockDB.AssertExpectations(t)
+}
+
 // TestService_SaveOrder_Success проверяет (сохранено в БД -> сохранено в кэш)
 func TestService_SaveOrder_Success(t *testing.T) {
 	// --- Arrange ---
@@ -187,6 +289,50 @@ func TestService_SaveOrder_Success(t *testing.T) {
 	mockCache.AssertExpectations(t)
 }
 
+// TestService_SaveOrder_PublishesEvent проверяет (сохранено в БД -> событие подписчикам)
+func TestService_SaveOrder_PublishesEvent(t *testing.T) {
+	// --- Arrange ---
+	mockDB := new(MockDB)
+	mockCache := new(MockCache)
+	service := NewService(mockDB, mockCache)
+	sub := service.Subscribe(1)
+	defer sub.Close()
+
+	mockDB.On("SaveOrder", mock.Anything, mock.Anything).Return(nil).Once()
+	mockDB.On("SaveOrder", mock.Anything, mock.Anything).Return(errors.New("db down")).Once()
+	mockCache.On("Set", mock.Anything, mock.Anything).Return()
+
+	// --- Act ---
+	require.NoError(t, service.SaveOrder(context.Background(), model.OrderData{OrderUID: "saved"}))
+	require.Error(t, service.SaveOrder(context.Background(), model.OrderData{OrderUID: "failed"}))
+
+	// --- Assert ---
+	ev := <-sub.C
+	assert.Equal(t, "saved", ev.Order.OrderUID)
+	assert.Empty(t, sub.C, "о несохраненном заказе событие не публикуется")
+}
+
+// TestBroadcaster_SlowSubscriber проверяет, что медленный подписчик не блокирует публикацию
+func TestBroadcaster_SlowSubscriber(t *testing.T) {
+	b := NewBroadcaster()
+	slow := b.Subscribe(1)
+	fast := b.Subscribe(3)
+
+	for i := range 3 {
+		b.Publish(OrderEvent{Order: &model.OrderData{OrderUID: fmt.Sprint(i)}})
+	}
+
+	assert.Len(t, fast.C, 3)
+	assert.Len(t, slow.C, 1)
+	assert.Equal(t, uint64(2), slow.Dropped())
+
+	// После закрытия рассыльщика каналы подписок закрыты
+	b.Close()
+	<-slow.C
+	_, ok := <-slow.C
+	assert.False(t, ok)
+}
+
 // TestService_SaveOrder_DBError проверяет (ошибка в БД -> кэш не обновлен)
 func TestService_SaveOrder_DBError(t *testing.T) {
 	// --- Arrange ---
@@ -212,6 +358,50 @@ func TestService_SaveOrder_DBError(t *testing.T) {
 	mockDB.AssertExpectations(t)
 }
 
+// TestService_SaveOrders_PartialFail проверяет, что в кэш попадают только сохраненные заказы
+func TestService_SaveOrders_PartialFail(t *testing.T) {
+	// --- Arrange ---
+	mockDB := new(MockDB)
+	mockCache := new(MockCache)
+	service := NewService(mockDB, mockCache)
+
+	orders := []model.OrderData{{OrderUID: "uid1"}, {OrderUID: "uid2"}}
+	orderErr := errors.New("conflict")
+
+	mockDB.On("SaveOrders", mock.Anything, orders).Return([]error{nil, orderErr}, nil).Once()
+	mockCache.On("Set", "uid1", &orders[0]).Return().Once()
+
+	// --- Act ---
+	errs, err := service.SaveOrders(context.Background(), orders)
+
+	// --- Assert ---
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], orderErr)
+	mockCache.AssertNotCalled(t, "Set", "uid2", mock.Anything)
+	mockDB.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+// TestService_SaveOrders_DBError проверяет общую ошибку сохранения пачки
+func TestService_SaveOrders_DBError(t *testing.T) {
+	mockDB := new(MockDB)
+	mockCache := new(MockCache)
+	service := NewService(mockDB, mockCache)
+
+	orders := []model.OrderData{{OrderUID: "uid1"}}
+	dbError := errors.New("db connection failed")
+	mockDB.On("SaveOrders", mock.Anything, orders).Return(nil, dbError).Once()
+
+	errs, err := service.SaveOrders(context.Background(), orders)
+
+	require.Error(t, err)
+	assert.Nil(t, errs)
+	assert.ErrorIs(t, err, dbError)
+	mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything)
+}
+
 // TestService_Close_Simple проверяет вызов db.Close()
 func TestService_Close_Simple(t *testing.T) {
 	mockDB := new(MockDB)
@@ -267,6 +457,24 @@ func TestService_InvalidateOrder(t *testing.T) {
 	mockCache.AssertExpectations(t)
 }
 
+// TestService_CacheStats проверяет получение статистики из кэша, который ее собирает
+func TestService_CacheStats(t *testing.T) {
+	// 1. MemoryCache собирает статистику
+	cache := NewMemoryCache(time.Minute)
+	defer cache.Close()
+	s := NewService(new(MockDB), cache)
+	cache.Get("missing")
+
+	stats, ok := s.CacheStats()
+	require.True(t, ok)
+	assert.Equal(t, uint64(1), stats.Misses)
+
+	// 2. Мок кэша статистику не поддерживает
+	s = NewService(new(MockDB), new(MockCache))
+	_, ok = s.CacheStats()
+	assert.False(t, ok)
+}
+
 // TestService_warmUpCache_Success (тестируем неэкспортируемый метод)
 func TestService_warmUpCache_Success(t *testing.T) {
 	mockDB := new(MockDB)
@@ -277,19 +485,18 @@ func TestService_warmUpCache_Success(t *testing.T) {
 	order2 := &model.OrderData{OrderUID: "uid2"}
 	uids := []string{"uid1", "uid2"}
 
-	// 1. Ожидаем GetRecentOrderUIDs
-	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything).Return(uids, nil).Once()
+	// 1. Ожидаем одну неполную страницу GetRecentOrderUIDs
+	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything, (*OrderKey)(nil), 500).Return(orderKeys(uids...), nil).Once()
 
-	// 2. Ожидаем GetOrderByUID для каждого uid
-	mockDB.On("GetOrderByUID", mock.Anything, "uid1").Return(order1, nil).Once()
-	mockDB.On("GetOrderByUID", mock.Anything, "uid2").Return(order2, nil).Once()
+	// 2. Ожидаем один запрос GetOrdersByUIDs на все uid
+	mockDB.On("GetOrdersByUIDs", mock.Anything, uids).Return([]*model.OrderData{order1, order2}, nil).Once()
 
 	// 3. Ожидаем Set для каждого заказа
 	mockCache.On("Set", "uid1", order1).Return().Once()
 	mockCache.On("Set", "uid2", order2).Return().Once()
 
 	// 4. Вызываем метод
-	err := s.warmUpCache(context.Background(), time.Time{}) // time.Time{} - просто заглушка
+	err := s.warmUpCache(context.Background(), WarmUpOptions{})
 
 	// 5. Проверяем
 	assert.NoError(t, err)
@@ -297,7 +504,7 @@ func TestService_warmUpCache_Success(t *testing.T) {
 	mockCache.AssertExpectations(t)
 }
 
-// TestService_warmUpCache_PartialFail (ошибка при загрузке одного из заказов)
+// TestService_warmUpCache_PartialFail (один из заказов не загрузился)
 func TestService_warmUpCache_PartialFail(t *testing.T) {
 	mockDB := new(MockDB)
 	mockCache := new(MockCache)
@@ -305,26 +512,167 @@ func TestService_warmUpCache_PartialFail(t *testing.T) {
 
 	order2 := &model.OrderData{OrderUID: "uid2"}
 	uids := []string{"uid1-fail", "uid2"}
-	dbError := errors.New("failed to get uid1")
 
 	// 1. Ожидаем GetRecentOrderUIDs
-	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything).Return(uids, nil).Once()
+	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything, (*OrderKey)(nil), 500).Return(orderKeys(uids...), nil).Once()
 
-	// 2. Ожидаем GetOrderByUID для uid1 (вернет ошибку)
-	mockDB.On("GetOrderByUID", mock.Anything, "uid1-fail").Return(nil, dbError).Once()
-	//    Ожидаем GetOrderByUID для uid2 (вернет успех)
-	mockDB.On("GetOrderByUID", mock.Anything, "uid2").Return(order2, nil).Once()
+	// 2. Ожидаем GetOrdersByUIDs, который вернет только uid2
+	mockDB.On("GetOrdersByUIDs", mock.Anything, uids).Return([]*model.OrderData{order2}, nil).Once()
 
 	// 3. Ожидаем Set только для uid2
 	mockCache.On("Set", "uid2", order2).Return().Once()
 
 	// 4. Вызываем метод
-	err := s.warmUpCache(context.Background(), time.Time{})
+	err := s.warmUpCache(context.Background(), WarmUpOptions{})
 
 	// 5. Проверяем
-	assert.NoError(t, err) // Ошибки в цикле логируются, но не прерывают прогрев
+	assert.NoError(t, err) // Незагруженные заказы логируются, но не прерывают прогрев
 	mockDB.AssertExpectations(t)
 	mockCache.AssertExpectations(t)
 	// Убеждаемся, что Set не вызывался для "uid1-fail"
 	mockCache.AssertNotCalled(t, "Set", "uid1-fail", mock.Anything)
 }
+
+// TestService_warmUpCache_PageFail (ошибка при загрузке страницы не прерывает прогрев)
+func TestService_warmUpCache_PageFail(t *testing.T) {
+	mockDB := new(MockDB)
+	mockCache := new(MockCache)
+	s := &Service{db: mockDB, cache: mockCache}
+
+	uids := []string{"uid1", "uid2", "uid3"}
+	last := &model.OrderData{OrderUID: "uid3"}
+
+	// 1. Первая страница не загружается, вторая загружается
+	keys := orderKeys(uids...)
+	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything, (*OrderKey)(nil), 2).Return(keys[:2], nil).Once()
+	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything, &keys[1], 2).Return(keys[2:], nil).Once()
+	mockDB.On("GetOrdersByUIDs", mock.Anything, uids[:2]).Return(nil, errors.New("db down")).Once()
+	mockDB.On("GetOrdersByUIDs", mock.Anything, uids[2:]).Return([]*model.OrderData{last}, nil).Once()
+	mockCache.On("Set", last.OrderUID, last).Return().Once()
+
+	// 2. Вызываем метод
+	err := s.warmUpCache(context.Background(), WarmUpOptions{PageSize: 2, Concurrency: 2})
+
+	// 3. Проверяем
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+// TestService_warmUpCache_MaxEntries (прогрев останавливается при достижении лимита)
+func TestService_warmUpCache_MaxEntries(t *testing.T) {
+	mockDB := new(MockDB)
+	mockCache := new(MockCache)
+	s := &Service{db: mockDB, cache: mockCache}
+
+	orders := []*model.OrderData{{OrderUID: "uid1"}, {OrderUID: "uid2"}, {OrderUID: "uid3"}}
+	uids := []string{"uid1", "uid2", "uid3", "uid4"}
+
+	// 1. Вторая страница загружается, но в кэш попадает только то, что влезает в лимит
+	keys := orderKeys(uids...)
+	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything, (*OrderKey)(nil), 2).Return(keys[:2], nil).Once()
+	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything, &keys[1], 2).Return(keys[2:], nil).Once()
+	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything, &keys[3], 2).Return(nil, nil).Maybe()
+	mockDB.On("GetOrdersByUIDs", mock.Anything, uids[:2]).Return(orders[:2], nil).Once()
+	mockDB.On("GetOrdersByUIDs", mock.Anything, uids[2:]).Return(orders[2:], nil).Once()
+	mockCache.On("Set", "uid1", orders[0]).Return().Once()
+	mockCache.On("Set", "uid2", orders[1]).Return().Once()
+
+	// 2. Вызываем метод
+	err := s.warmUpCache(context.Background(), WarmUpOptions{PageSize: 2, Concurrency: 1, MaxEntries: 2})
+
+	// 3. Проверяем
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "Set", "uid3", mock.Anything)
+}
+
+// TestService_warmUpCache_Canceled (прогрев прерывается отменой контекста)
+func TestService_warmUpCache_Canceled(t *testing.T) {
+	mockDB := new(MockDB)
+	mockCache := new(MockCache)
+	s := &Service{db: mockDB, cache: mockCache}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(orderKeys("uid1"), nil).Maybe()
+	mockDB.On("GetOrdersByUIDs", mock.Anything, mock.Anything).Return(nil, context.Canceled).Maybe()
+
+	err := s.warmUpCache(ctx, WarmUpOptions{})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything)
+}
+
+// TestService_warmUpCache_ListFail (ошибка чтения списка UID прерывает прогрев)
+func TestService_warmUpCache_ListFail(t *testing.T) {
+	mockDB := new(MockDB)
+	mockCache := new(MockCache)
+	s := &Service{db: mockDB, cache: mockCache}
+	dbErr := errors.New("db down")
+
+	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything, (*OrderKey)(nil), 500).Return(nil, dbErr).Once()
+
+	err := s.warmUpCache(context.Background(), WarmUpOptions{})
+
+	assert.ErrorIs(t, err, dbErr)
+	mockDB.AssertExpectations(t)
+}
+
+// TestService_runWarmUp_Retry проверяет, что после ошибки прогрев повторяется
+// и компонент становится готовым
+func TestService_runWarmUp_Retry(t *testing.T) {
+	// --- Arrange ---
+	mockDB := new(MockDB)
+	mockCache := new(MockCache)
+	s := &Service{db: mockDB, cache: mockCache}
+	registry := health.NewRegistry()
+
+	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything, (*OrderKey)(nil), 500).Return(nil, errors.New("db down")).Once()
+	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything, (*OrderKey)(nil), 500).Return(nil, nil).Once()
+	mockCache.On("Count").Return(0)
+
+	// --- Act ---
+	s.runWarmUp(context.Background(), WarmUpOptions{Retries: 1, RetryDelay: time.Millisecond, Health: registry.Component("cache_warmup")})
+
+	// --- Assert ---
+	report := registry.Readiness(context.Background())
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Equal(t, "не удалось получить список заказов: db down", report.Components["cache_warmup"].LastError)
+	mockDB.AssertExpectations(t)
+}
+
+// TestService_runWarmUp_GiveUp проверяет, что после исчерпания повторов сервис
+// готов без прогрева, а ошибка остается в состоянии компонента
+func TestService_runWarmUp_GiveUp(t *testing.T) {
+	// --- Arrange ---
+	mockDB := new(MockDB)
+	s := &Service{db: mockDB, cache: new(MockCache)}
+	registry := health.NewRegistry()
+
+	mockDB.On("GetRecentOrderUIDs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("db down")).Times(3)
+
+	// --- Act ---
+	s.runWarmUp(context.Background(), WarmUpOptions{Retries: 2, RetryDelay: time.Millisecond, Health: registry.Component("cache_warmup")})
+
+	// --- Assert ---
+	state := registry.Readiness(context.Background()).Components["cache_warmup"]
+	assert.Equal(t, health.StatusOK, state.Status)
+	assert.Contains(t, state.LastError, "db down")
+	mockDB.AssertExpectations(t)
+}
+
+// TestWarmUpBudget_MaxBytes проверяет лимит памяти прогрева
+func TestWarmUpBudget_MaxBytes(t *testing.T) {
+	budget := &warmUpBudget{maxBytes: 100}
+
+	assert.True(t, budget.reserve(60))
+	assert.False(t, budget.reserve(60), "заказ не влезает в оставшийся бюджет")
+	assert.False(t, budget.reserve(10), "после исчерпания бюджет больше не выдается")
+
+	entries, bytes, exhausted := budget.usage()
+	assert.Equal(t, 1, entries)
+	assert.Equal(t, int64(60), bytes)
+	assert.True(t, exhausted)
+}
@@ -0,0 +1,227 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"l1/internal/health"
+)
+
+// WarmUpOptions — настройки прогрева кэша.
+type WarmUpOptions struct {
+	Window      time.Duration // за какой период прогревать заказы
+	PageSize    int           // сколько заказов загружается одним запросом
+	Concurrency int           // сколько страниц загружается параллельно
+	MaxEntries  int           // максимум заказов в кэше после прогрева, 0 — без ограничения
+	MaxBytes    int64         // примерный лимит памяти под прогретые заказы, 0 — без ограничения
+	Retries     int           // сколько раз повторить прогрев после ошибки
+	RetryDelay  time.Duration // задержка перед первым повтором, дальше удваивается до maxWarmUpRetryDelay
+
+	Health *health.Component // куда сообщать о завершении прогрева, может быть nil
+}
+
+// withDefaults подставляет значения по умолчанию для незаданных настроек.
+func (o WarmUpOptions) withDefaults() WarmUpOptions {
+	if o.Window <= 0 {
+		o.Window = 7 * 24 * time.Hour
+	}
+	if o.PageSize <= 0 {
+		o.PageSize = 500
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 1
+	}
+	if o.Retries < 0 {
+		o.Retries = 0
+	}
+	if o.RetryDelay <= 0 {
+		o.RetryDelay = time.Second
+	}
+	return o
+}
+
+// maxWarmUpRetryDelay ограничивает задержку между повторами прогрева.
+const maxWarmUpRetryDelay = time.Minute
+
+// runWarmUp прогревает кэш, повторяя прогрев с экспоненциальной задержкой после
+// ошибок. Если все попытки неудачны, кэш работает без прогрева: компонент
+// отмечается готовым, а последняя ошибка остается в его состоянии для диагностики.
+func (s *Service) runWarmUp(ctx context.Context, opts WarmUpOptions) {
+	opts = opts.withDefaults()
+	delay := opts.RetryDelay
+	for attempt := 0; ; attempt++ {
+		err := s.warmUpCache(ctx, opts)
+		if err == nil {
+			log.Printf("Фоновый прогрев кэша завершён: %d заказов", s.cache.Count())
+			opts.Health.SetReady()
+			return
+		}
+		if ctx.Err() != nil {
+			log.Printf("Фоновый прогрев кэша прерван: %v", err)
+			return
+		}
+		if attempt >= opts.Retries {
+			log.Printf("Ошибка фонового прогрева кэша, продолжаем без прогрева: %v", err)
+			opts.Health.SetError(err)
+			opts.Health.SetReady()
+			return
+		}
+
+		log.Printf("Ошибка фонового прогрева кэша (попытка %d из %d), повтор через %v: %v", attempt+1, opts.Retries+1, delay, err)
+		opts.Health.SetError(err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxWarmUpRetryDelay)
+	}
+}
+
+// warmUpBudget учитывает, сколько заказов и памяти уже занято прогревом.
+type warmUpBudget struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	entries    int
+	bytes      int64
+	exhausted  bool
+}
+
+// reserve резервирует место под заказ. Возвращает false, если бюджет исчерпан.
+func (b *warmUpBudget) reserve(size int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.exhausted {
+		return false
+	}
+	if (b.maxEntries > 0 && b.entries >= b.maxEntries) || (b.maxBytes > 0 && b.bytes+size > b.maxBytes) {
+		b.exhausted = true
+		return false
+	}
+	b.entries++
+	b.bytes += size
+	return true
+}
+
+func (b *warmUpBudget) usage() (int, int64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.entries, b.bytes, b.exhausted
+}
+
+// warmUpCache выполняет прогрев кэша при старте: заказы за opts.Window загружаются
+// страницами по opts.PageSize, начиная с самых новых, в opts.Concurrency потоков.
+// Список UID тоже читается страницами, поэтому в памяти не держится целиком.
+// Прогрев останавливается при исчерпании лимитов или отмене ctx.
+func (s *Service) warmUpCache(ctx context.Context, opts WarmUpOptions) error {
+	opts = opts.withDefaults()
+	since := time.Now().Add(-opts.Window)
+	log.Printf("Прогрев кэша: загружаем заказы за %v", opts.Window)
+
+	loadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Страницы UID читаются по ключу (date_created, order_uid) последнего заказа
+	// предыдущей страницы, пока загрузчики обрабатывают уже прочитанные.
+	pages := make(chan []string)
+	var listErr error
+	listed := make(chan struct{})
+	go func() {
+		defer close(listed)
+		defer close(pages)
+		var after *OrderKey
+		for {
+			keys, err := s.db.GetRecentOrderUIDs(loadCtx, since, after, opts.PageSize)
+			if err != nil {
+				if loadCtx.Err() == nil {
+					listErr = fmt.Errorf("не удалось получить список заказов: %w", err)
+				}
+				return
+			}
+			if len(keys) == 0 {
+				return
+			}
+			page := make([]string, len(keys))
+			for i, key := range keys {
+				page[i] = key.UID
+			}
+			select {
+			case pages <- page:
+			case <-loadCtx.Done():
+				return
+			}
+			if len(keys) < opts.PageSize {
+				return
+			}
+			after = &keys[len(keys)-1]
+		}
+	}()
+
+	budget := &warmUpBudget{maxEntries: opts.MaxEntries, maxBytes: opts.MaxBytes}
+	var processed int
+	var mu sync.Mutex // защищает processed
+	var wg sync.WaitGroup
+	for range opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range pages {
+				if !s.warmUpPage(loadCtx, page, budget) {
+					cancel() // бюджет исчерпан — остальные страницы не нужны
+					return
+				}
+				mu.Lock()
+				processed += len(page)
+				done := processed
+				mu.Unlock()
+				log.Printf("Прогрев кэша: обработано %d заказов", done)
+			}
+		}()
+	}
+	wg.Wait()
+	cancel() // загрузчики остановились — чтение списка больше не нужно
+	<-listed
+
+	entries, bytes, exhausted := budget.usage()
+	log.Printf("Прогрузили в кэш %d заказов (~%d КБ)", entries, bytes/1024)
+	if exhausted {
+		log.Printf("Прогрев кэша остановлен: достигнут лимит (заказов: %d, байт: %d)", opts.MaxEntries, opts.MaxBytes)
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("прогрев кэша прерван: %w", err)
+	}
+	return listErr
+}
+
+// warmUpPage загружает страницу заказов и кладет их в кэш.
+// Возвращает false, если бюджет прогрева исчерпан.
+func (s *Service) warmUpPage(ctx context.Context, page []string, budget *warmUpBudget) bool {
+	// Идем напрямую в БД, чтобы загрузить данные
+	orders, err := s.db.GetOrdersByUIDs(ctx, page)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Пропускаем %d заказов при прогреве: %v", len(page), err)
+		}
+		return true
+	}
+	if len(orders) < len(page) {
+		log.Printf("При прогреве не найдено %d заказов из %d", len(page)-len(orders), len(page))
+	}
+
+	for _, order := range orders {
+		if !budget.reserve(orderSize(order)) {
+			return false
+		}
+		// Напрямую кладем в кэш
+		s.cache.Set(order.OrderUID, order)
+	}
+	return true
+}
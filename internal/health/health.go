@@ -0,0 +1,211 @@
+// Package health собирает состояние компонентов сервиса для проверок живости и готовности.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Status — состояние компонента или сервиса в целом.
+type Status string
+
+const (
+	// StatusOK — компонент работает.
+	StatusOK Status = "ok"
+	// StatusPending — компонент еще не готов (например, идет прогрев кэша).
+	StatusPending Status = "pending"
+	// StatusDown — компонент не работает.
+	StatusDown Status = "down"
+)
+
+// checkTimeout ограничивает время активных проверок при запросе готовности.
+const checkTimeout = 2 * time.Second
+
+// ComponentStatus — состояние компонента для JSON-ответа.
+type ComponentStatus struct {
+	Status      Status     `json:"status"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Component — компонент, который сам сообщает о своем состоянии.
+// Методы безопасны для nil, чтобы компоненты можно было использовать без реестра.
+type Component struct {
+	mu    sync.Mutex
+	state ComponentStatus
+}
+
+// SetReady отмечает компонент работающим. Последняя ошибка сохраняется для диагностики.
+func (c *Component) SetReady() {
+	c.set(StatusOK, nil)
+}
+
+// SetError отмечает компонент неработающим из-за err.
+func (c *Component) SetError(err error) {
+	c.set(StatusDown, err)
+}
+
+func (c *Component) set(status Status, err error) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.state.Status = status
+	c.state.UpdatedAt = now
+	if err != nil {
+		c.state.LastError = err.Error()
+		c.state.LastErrorAt = &now
+	}
+}
+
+func (c *Component) snapshot() ComponentStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// CheckFunc — активная проверка, выполняемая при каждом запросе готовности.
+type CheckFunc func(ctx context.Context) error
+
+// Registry хранит компоненты и проверки сервиса.
+type Registry struct {
+	mu         sync.RWMutex
+	components map[string]*Component
+	checks     map[string]CheckFunc
+	fatal      error // причина, по которой процесс больше не выполняет свою работу
+}
+
+// NewRegistry создает пустой реестр.
+func NewRegistry() *Registry {
+	return &Registry{
+		components: make(map[string]*Component),
+		checks:     make(map[string]CheckFunc),
+	}
+}
+
+// Component регистрирует компонент с именем name в состоянии StatusPending
+// и возвращает его. Повторный вызов с тем же именем возвращает тот же компонент.
+func (r *Registry) Component(name string) *Component {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c, ok := r.components[name]; ok {
+		return c
+	}
+	c := &Component{state: ComponentStatus{Status: StatusPending, UpdatedAt: time.Now()}}
+	r.components[name] = c
+	return c
+}
+
+// AddCheck регистрирует активную проверку компонента name.
+func (r *Registry) AddCheck(name string, check CheckFunc) {
+	r.Component(name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+}
+
+// Report — ответ проверки готовности.
+type Report struct {
+	Status     Status                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// Readiness выполняет активные проверки и собирает состояние всех компонентов.
+// Сервис готов, только если все компоненты в состоянии StatusOK.
+func (r *Registry) Readiness(ctx context.Context) Report {
+	r.mu.RLock()
+	components := make(map[string]*Component, len(r.components))
+	for name, c := range r.components {
+		components[name] = c
+	}
+	checks := make(map[string]CheckFunc, len(r.checks))
+	for name, check := range r.checks {
+		checks[name] = check
+	}
+	r.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(c *Component, check CheckFunc) {
+			defer wg.Done()
+			if err := check(ctx); err != nil {
+				c.SetError(err)
+				return
+			}
+			c.SetReady()
+		}(components[name], check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Components: make(map[string]ComponentStatus, len(components))}
+	for name, c := range components {
+		state := c.snapshot()
+		report.Components[name] = state
+		if state.Status != StatusOK {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// SetFatal отмечает процесс неживым: компонент, без которого сервис не выполняет
+// свою работу, остановился и сам не восстановится. После этого проверка живости
+// отвечает 503, и оркестратор перезапускает процесс.
+func (r *Registry) SetFatal(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fatal == nil {
+		r.fatal = err
+	}
+}
+
+// LivenessHandler отвечает 200, пока процесс жив и обслуживает запросы,
+// и 503 после SetFatal.
+func (r *Registry) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		r.mu.RLock()
+		fatal := r.fatal
+		r.mu.RUnlock()
+		if fatal != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": string(StatusDown), "error": fatal.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]Status{"status": StatusOK})
+	})
+}
+
+// ReadinessHandler отвечает 200, если все компоненты готовы, и 503 в остальных случаях.
+// В теле ответа — состояние и последняя ошибка каждого компонента.
+func (r *Registry) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Readiness(req.Context())
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, report)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Ошибка кодирования ответа проверки состояния: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRegistry_Readiness проверяет, что сервис готов, только когда готовы все компоненты
+func TestRegistry_Readiness(t *testing.T) {
+	// --- Arrange ---
+	r := NewRegistry()
+	kafka := r.Component("kafka")
+	warmUp := r.Component("cache_warmup")
+	dbErr := errors.New("connection refused")
+	var dbDown bool
+	r.AddCheck("postgres", func(context.Context) error {
+		if dbDown {
+			return dbErr
+		}
+		return nil
+	})
+
+	// --- Act & Assert ---
+	report := r.Readiness(context.Background())
+	assert.Equal(t, StatusDown, report.Status, "пока компоненты не сообщили о готовности")
+	assert.Equal(t, StatusPending, report.Components["kafka"].Status)
+	assert.Equal(t, StatusOK, report.Components["postgres"].Status)
+
+	kafka.SetReady()
+	warmUp.SetReady()
+	assert.Equal(t, StatusOK, r.Readiness(context.Background()).Status)
+
+	dbDown = true
+	report = r.Readiness(context.Background())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, StatusDown, report.Components["postgres"].Status)
+	assert.Equal(t, dbErr.Error(), report.Components["postgres"].LastError)
+
+	dbDown = false
+	report = r.Readiness(context.Background())
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Equal(t, dbErr.Error(), report.Components["postgres"].LastError, "последняя ошибка сохраняется после восстановления")
+	assert.NotNil(t, report.Components["postgres"].LastErrorAt)
+}
+
+// TestRegistry_ComponentIsShared проверяет, что компонент с одним именем регистрируется один раз
+func TestRegistry_ComponentIsShared(t *testing.T) {
+	r := NewRegistry()
+	assert.Same(t, r.Component("kafka"), r.Component("kafka"))
+}
+
+// TestComponent_NilSafe проверяет, что nil-компонент можно использовать без реестра
+func TestComponent_NilSafe(t *testing.T) {
+	var c *Component
+	assert.NotPanics(t, func() {
+		c.SetReady()
+		c.SetError(errors.New("any"))
+	})
+}
+
+// TestHandlers проверяет коды и тела ответов /healthz и /readyz
+func TestHandlers(t *testing.T) {
+	r := NewRegistry()
+	kafka := r.Component("kafka")
+	kafka.SetError(errors.New("broker down"))
+
+	rr := httptest.NewRecorder()
+	r.LivenessHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	r.ReadinessHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var report Report
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, "broker down", report.Components["kafka"].LastError)
+
+	kafka.SetReady()
+	rr = httptest.NewRecorder()
+	r.ReadinessHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+// TestLivenessHandler_Fatal проверяет, что после SetFatal процесс считается неживым
+func TestLivenessHandler_Fatal(t *testing.T) {
+	r := NewRegistry()
+	r.SetFatal(errors.New("consumer stopped"))
+	r.SetFatal(errors.New("second")) // сохраняется первая причина
+
+	rr := httptest.NewRecorder()
+	r.LivenessHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.JSONEq(t, `{"status":"down","error":"consumer stopped"}`, rr.Body.String())
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Метрики приложения.
+var (
+	// ConsumerMessages — полученные из Kafka сообщения.
+	ConsumerMessages = Default.NewCounterVec("orders_consumer_messages_total",
+		"Сообщения, полученные consumer'ом из Kafka.")
+	// ConsumerFailures — сообщения, которые не удалось обработать, по этапам.
+	ConsumerFailures = Default.NewCounterVec("orders_consumer_failures_total",
+		"Сообщения, которые не удалось обработать, по этапу обработки.", "stage")
+	// ConsumerDeadLetters — сообщения, отправленные в DLQ, по этапам.
+	ConsumerDeadLetters = Default.NewCounterVec("orders_consumer_dead_letters_total",
+		"Сообщения, отправленные в dead-letter топик, по этапу обработки.", "stage")
+	// ConsumerLag — отставание consumer'а от конца партиции.
+	ConsumerLag = Default.NewGaugeVec("orders_consumer_lag",
+		"Отставание consumer'а от конца партиции в сообщениях.", "partition")
+
+	// ServiceDuration — длительность операций сервиса заказов по источнику данных.
+	ServiceDuration = Default.NewHistogramVec("orders_service_duration_seconds",
+		"Длительность операций сервиса заказов по источнику данных (cache или db).", nil, "method", "source")
+
+	// HTTPDuration — длительность HTTP-запросов по маршруту и коду ответа.
+	HTTPDuration = Default.NewHistogramVec("http_request_duration_seconds",
+		"Длительность HTTP-запросов по маршруту и коду ответа.", nil, "route", "status")
+
+	// GRPCDuration — длительность gRPC-вызовов по методу и коду ответа.
+	GRPCDuration = Default.NewHistogramVec("grpc_request_duration_seconds",
+		"Длительность gRPC-вызовов по методу и коду ответа.", nil, "method", "code")
+
+	// StreamClients — открытые подписки на ленту заказов по транспорту (sse).
+	StreamClients = Default.NewGaugeVec("orders_stream_clients",
+		"Открытые подписки на ленту новых заказов по транспорту.", "transport")
+	// StreamDropped — события ленты, пропущенные из-за медленных клиентов.
+	StreamDropped = Default.NewCounterVec("orders_stream_dropped_events_total",
+		"События ленты новых заказов, пропущенные из-за переполнения буфера клиента.", "transport")
+	// StreamDisconnects — клиенты ленты, отключенные сервером, по причине.
+	StreamDisconnects = Default.NewCounterVec("orders_stream_disconnects_total",
+		"Клиенты ленты новых заказов, отключенные сервером, по причине (write_error, shutdown).", "transport", "reason")
+)
+
+// Источники данных для ServiceDuration.
+const (
+	SourceCache = "cache"
+	SourceDB    = "db"
+)
+
+// ObserveSince учитывает в гистограмме время, прошедшее с start.
+func ObserveSince(h *Histogram, start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Handler отдает метрики реестра Default.
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// InstrumentHandler измеряет длительность запросов к next. route — шаблон маршрута,
+// а не фактический путь, чтобы число наборов меток не росло с каждым UID.
+func InstrumentHandler(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		ObserveSince(HTTPDuration.With(route, strconv.Itoa(rec.status)), start)
+	})
+}
+
+// statusRecorder запоминает код ответа.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap позволяет http.ResponseController добраться до исходного ResponseWriter.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
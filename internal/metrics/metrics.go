@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"sync/atomic"
+)
+
+// --- Counter ---
+
+// Counter — монотонно растущий счетчик.
+type Counter struct {
+	v atomicFloat
+}
+
+// Inc увеличивает счетчик на 1.
+func (c *Counter) Inc() { c.v.add(1) }
+
+// Add увеличивает счетчик на v; отрицательные значения игнорируются.
+func (c *Counter) Add(v float64) {
+	if v > 0 {
+		c.v.add(v)
+	}
+}
+
+// CounterVec — счетчики с метками.
+type CounterVec struct {
+	desc
+	series series[Counter]
+}
+
+// NewCounterVec регистрирует счетчик с метками.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{
+		desc:   desc{fqName: name, help: help, typ: "counter", labels: labels},
+		series: series[Counter]{values: make(map[string]*labeled[Counter]), newFn: func() *Counter { return &Counter{} }},
+	}
+	r.register(c)
+	return c
+}
+
+// With возвращает счетчик для значений меток (в порядке их объявления).
+func (c *CounterVec) With(labelValues ...string) *Counter {
+	return c.series.get(&c.desc, labelValues)
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.writeHeader(w)
+	for _, s := range c.series.sorted() {
+		fmt.Fprintf(w, "%s%s %s\n", c.fqName, c.labelPairs(s.labels), formatFloat(s.value.v.load()))
+	}
+}
+
+// --- Gauge ---
+
+// Gauge — значение, которое может как расти, так и уменьшаться.
+type Gauge struct {
+	v atomicFloat
+}
+
+// Set устанавливает значение.
+func (g *Gauge) Set(v float64) { g.v.set(v) }
+
+// Add изменяет значение на v.
+func (g *Gauge) Add(v float64) { g.v.add(v) }
+
+// GaugeVec — значения с метками.
+type GaugeVec struct {
+	desc
+	series series[Gauge]
+}
+
+// NewGaugeVec регистрирует значение с метками.
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{
+		desc:   desc{fqName: name, help: help, typ: "gauge", labels: labels},
+		series: series[Gauge]{values: make(map[string]*labeled[Gauge]), newFn: func() *Gauge { return &Gauge{} }},
+	}
+	r.register(g)
+	return g
+}
+
+// With возвращает значение для значений меток (в порядке их объявления).
+func (g *GaugeVec) With(labelValues ...string) *Gauge {
+	return g.series.get(&g.desc, labelValues)
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	g.writeHeader(w)
+	for _, s := range g.series.sorted() {
+		fmt.Fprintf(w, "%s%s %s\n", g.fqName, g.labelPairs(s.labels), formatFloat(s.value.v.load()))
+	}
+}
+
+// --- Func ---
+
+// funcMetric — метрика без меток, значение которой вычисляется при каждой отдаче.
+type funcMetric struct {
+	desc
+	fn func() float64
+}
+
+// NewGaugeFunc регистрирует значение, вычисляемое функцией fn при отдаче метрик.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{desc: desc{fqName: name, help: help, typ: "gauge"}, fn: fn})
+}
+
+// NewCounterFunc регистрирует счетчик, значение которого берется из fn при отдаче метрик.
+// fn должна возвращать монотонно растущее значение.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{desc: desc{fqName: name, help: help, typ: "counter"}, fn: fn})
+}
+
+func (f *funcMetric) write(w *bufio.Writer) {
+	f.writeHeader(w)
+	fmt.Fprintf(w, "%s %s\n", f.fqName, formatFloat(f.fn()))
+}
+
+// --- Histogram ---
+
+// DefBuckets — границы корзин по умолчанию (в секундах), как в клиенте Prometheus.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram распределяет наблюдения по корзинам.
+type Histogram struct {
+	upperBounds []float64
+	counts      []atomic.Uint64 // по корзинам, не накопительно; последняя — выше всех границ
+	sum         atomicFloat
+}
+
+// Observe учитывает наблюдение v.
+func (h *Histogram) Observe(v float64) {
+	// Первая корзина, верхняя граница которой не меньше v
+	h.counts[sort.SearchFloat64s(h.upperBounds, v)].Add(1)
+	h.sum.add(v)
+}
+
+// HistogramVec — гистограммы с метками.
+type HistogramVec struct {
+	desc
+	series series[Histogram]
+}
+
+// NewHistogramVec регистрирует гистограмму с метками. Если buckets пуст, используются DefBuckets.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	bounds := append([]float64(nil), buckets...)
+	sort.Float64s(bounds)
+
+	h := &HistogramVec{
+		desc: desc{fqName: name, help: help, typ: "histogram", labels: labels},
+		series: series[Histogram]{values: make(map[string]*labeled[Histogram]), newFn: func() *Histogram {
+			return &Histogram{upperBounds: bounds, counts: make([]atomic.Uint64, len(bounds)+1)}
+		}},
+	}
+	r.register(h)
+	return h
+}
+
+// With возвращает гистограмму для значений меток (в порядке их объявления).
+func (h *HistogramVec) With(labelValues ...string) *Histogram {
+	return h.series.get(&h.desc, labelValues)
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.writeHeader(w)
+	for _, s := range h.series.sorted() {
+		hist := s.value
+		var cumulative uint64
+		for i, bound := range hist.upperBounds {
+			cumulative += hist.counts[i].Load()
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.fqName, h.labelPairs(s.labels, "le", formatFloat(bound)), cumulative)
+		}
+		// Общее число считается по корзинам, чтобы быть согласованным с ними
+		count := cumulative + hist.counts[len(hist.upperBounds)].Load()
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.fqName, h.labelPairs(s.labels, "le", "+Inf"), count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.fqName, h.labelPairs(s.labels), formatFloat(hist.sum.load()))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.fqName, h.labelPairs(s.labels), count)
+	}
+}
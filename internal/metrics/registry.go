@@ -0,0 +1,206 @@
+// Package metrics реализует сбор метрик и их отдачу в текстовом формате Prometheus.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// contentType — тип содержимого текстового формата Prometheus.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// collector — метрика (возможно, с метками), которую реестр умеет отдать.
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry хранит метрики и отдает их по HTTP.
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]collector
+}
+
+// NewRegistry создает пустой реестр.
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]collector)}
+}
+
+// Default — реестр, в котором регистрируются метрики приложения.
+var Default = NewRegistry()
+
+// register добавляет метрику. Повторная регистрация имени — ошибка программиста.
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.metrics[c.name()]; ok {
+		panic(fmt.Sprintf("metrics: метрика %s уже зарегистрирована", c.name()))
+	}
+	r.metrics[c.name()] = c
+}
+
+// Handler возвращает обработчик, отдающий все метрики реестра.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		bw := bufio.NewWriter(w)
+		r.WriteTo(bw)
+		if err := bw.Flush(); err != nil {
+			log.Printf("Ошибка отдачи метрик: %v", err)
+		}
+	})
+}
+
+// WriteTo пишет все метрики реестра в текстовом формате, упорядочив их по имени.
+func (r *Registry) WriteTo(w *bufio.Writer) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	collectors := make([]collector, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		collectors = append(collectors, r.metrics[name])
+	}
+	r.mu.RUnlock()
+
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// --- Общие части метрик с метками ---
+
+// desc — описание метрики.
+type desc struct {
+	fqName string
+	help   string
+	typ    string
+	labels []string
+}
+
+func (d *desc) name() string { return d.fqName }
+
+func (d *desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.fqName, escapeHelp(d.help), d.fqName, d.typ)
+}
+
+// labelPairs форматирует метки вида {a="1",b="2"}; extra добавляется в конец (например, le).
+func (d *desc) labelPairs(values []string, extra ...string) string {
+	if len(d.labels) == 0 && len(extra) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, l := range d.labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, l, escapeLabel(values[i]))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, extra[i], escapeLabel(extra[i+1]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// series хранит значения метрики по наборам меток.
+type series[T any] struct {
+	mu     sync.RWMutex
+	values map[string]*labeled[T]
+	newFn  func() *T
+}
+
+type labeled[T any] struct {
+	labels []string
+	value  *T
+}
+
+// get возвращает значение для набора меток, создавая его при первом обращении.
+func (s *series[T]) get(d *desc, labelValues []string) *T {
+	if len(labelValues) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s ожидает %d меток, передано %d", d.fqName, len(d.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	s.mu.RLock()
+	v, ok := s.values[key]
+	s.mu.RUnlock()
+	if ok {
+		return v.value
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok = s.values[key]; !ok {
+		v = &labeled[T]{labels: slices.Clone(labelValues), value: s.newFn()}
+		s.values[key] = v
+	}
+	return v.value
+}
+
+// sorted возвращает значения, упорядоченные по меткам, чтобы вывод был стабильным.
+func (s *series[T]) sorted() []*labeled[T] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]*labeled[T], 0, len(s.values))
+	for _, v := range s.values {
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return slices.Compare(out[i].labels, out[j].labels) < 0
+	})
+	return out
+}
+
+// atomicFloat — float64 с атомарными операциями.
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) add(v float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) set(v float64) { f.bits.Store(math.Float64bits(v)) }
+
+func (f *atomicFloat) load() float64 { return math.Float64frombits(f.bits.Load()) }
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
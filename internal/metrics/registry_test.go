@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// render возвращает метрики реестра в текстовом формате.
+func render(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	w := bufio.NewWriter(&b)
+	r.WriteTo(w)
+	require.NoError(t, w.Flush())
+	return b.String()
+}
+
+// TestRegistry_CounterAndGauge проверяет формат счетчиков и значений с метками
+func TestRegistry_CounterAndGauge(t *testing.T) {
+	r := NewRegistry()
+	failures := r.NewCounterVec("failures_total", "Ошибки по этапу.", "stage")
+	lag := r.NewGaugeVec("lag", "Отставание.", "partition")
+	r.NewGaugeFunc("size", "Размер.", func() float64 { return 3 })
+
+	failures.With("save").Inc()
+	failures.With("parse").Add(2)
+	failures.With("parse").Add(-1) // счетчик не уменьшается
+	lag.With("0").Set(10)
+	lag.With("0").Add(-4)
+
+	expected := `# HELP failures_total Ошибки по этапу.
+# TYPE failures_total counter
+failures_total{stage="parse"} 2
+failures_total{stage="save"} 1
+# HELP lag Отставание.
+# TYPE lag gauge
+lag{partition="0"} 6
+# HELP size Размер.
+# TYPE size gauge
+size 3
+`
+	assert.Equal(t, expected, render(t, r))
+}
+
+// TestRegistry_Histogram проверяет накопительные корзины, сумму и количество
+func TestRegistry_Histogram(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("duration_seconds", "Длительность.", []float64{1, 0.1}, "route")
+
+	for _, v := range []float64{0.05, 0.1, 0.5, 5} {
+		h.With("/order/").Observe(v)
+	}
+
+	expected := `# HELP duration_seconds Длительность.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{route="/order/",le="0.1"} 2
+duration_seconds_bucket{route="/order/",le="1"} 3
+duration_seconds_bucket{route="/order/",le="+Inf"} 4
+duration_seconds_sum{route="/order/"} 5.65
+duration_seconds_count{route="/order/"} 4
+`
+	assert.Equal(t, expected, render(t, r))
+}
+
+// TestRegistry_EscapesLabels проверяет экранирование значений меток
+func TestRegistry_EscapesLabels(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("c_total", "C.", "v").With("a\"b\\c\nd").Inc()
+
+	assert.Contains(t, render(t, r), `c_total{v="a\"b\\c\nd"} 1`)
+}
+
+// TestRegistry_Panics проверяет ошибки программиста: повторное имя и неверное число меток
+func TestRegistry_Panics(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("c_total", "C.", "a", "b")
+
+	assert.Panics(t, func() { r.NewGaugeVec("c_total", "C.") })
+	assert.Panics(t, func() { c.With("only-one") })
+}
+
+// TestRegistry_Concurrency проверяет отсутствие гонок при параллельном обновлении
+func TestRegistry_Concurrency(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("c_total", "C.", "worker")
+	h := r.NewHistogramVec("h", "H.", nil)
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				c.With("w").Inc()
+				h.With().Observe(0.01)
+			}
+		}()
+	}
+	wg.Wait()
+
+	out := render(t, r)
+	assert.Contains(t, out, `c_total{worker="w"} 5000`)
+	assert.Contains(t, out, `h_count 5000`)
+}
+
+// TestInstrumentHandler проверяет учет длительности запросов по маршруту и коду ответа
+func TestInstrumentHandler(t *testing.T) {
+	handler := InstrumentHandler("/test/", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "нет", http.StatusNotFound)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test/abc", nil))
+
+	rr := httptest.NewRecorder()
+	Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, contentType, rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), `http_request_duration_seconds_count{route="/test/",status="404"} 1`)
+}
@@ -0,0 +1,260 @@
+// Package migrate применяет версионированные миграции схемы БД.
+//
+// Миграции лежат в каталоге migrations и встраиваются в бинарник. Каждая версия —
+// пара файлов NNNN_name.up.sql и NNNN_name.down.sql. Примененные версии
+// записываются в таблицу schema_migrations. Все изменения одного запуска идут
+// в одной транзакции под advisory-блокировкой, поэтому несколько экземпляров
+// сервиса, стартующих одновременно, не применяют миграции дважды.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+//go:embed migrations/*.sql
+var embedded embed.FS
+
+// lockKey — ключ advisory-блокировки, которой запуски миграций исключают друг друга.
+const lockKey int64 = 0x6c315f6d6967 // "l1_mig"
+
+// DB определяет, что Migrator использует для работы с базой данных.
+type DB interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Migration — одна версия схемы.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string // SQL применения
+	Down    string // SQL отката
+}
+
+// Status — миграция и время ее применения (нулевое, если не применена).
+type Status struct {
+	Migration
+	AppliedAt time.Time
+}
+
+// Applied сообщает, применена ли миграция.
+func (s Status) Applied() bool {
+	return !s.AppliedAt.IsZero()
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load читает миграции из корня fsys и возвращает их по возрастанию версии.
+// У каждой версии должны быть оба файла: up и down.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать каталог миграций: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		parts := fileName.FindStringSubmatch(e.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("имя файла миграции %s не соответствует формату NNNN_name.up.sql или NNNN_name.down.sql", e.Name())
+		}
+		version, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("некорректная версия миграции в имени %s", e.Name())
+		}
+		data, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать миграцию %s: %w", e.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = m
+		}
+		if m.Name != parts[2] {
+			return nil, fmt.Errorf("у версии %d две миграции: %s и %s", version, m.Name, parts[2])
+		}
+		if parts[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("у миграции %d_%s должны быть файлы up и down", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return migrations, nil
+}
+
+// Migrator применяет и откатывает миграции.
+type Migrator struct {
+	db         DB
+	migrations []Migration
+}
+
+// New создает Migrator со встроенными в бинарник миграциями.
+func New(db DB) (*Migrator, error) {
+	sub, err := fs.Sub(embedded, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(sub)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithMigrations(db, migrations), nil
+}
+
+// NewWithMigrations создает Migrator с заданным набором миграций.
+func NewWithMigrations(db DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// Up применяет все еще не примененные миграции и возвращает их. Если какая-то
+// из них завершилась ошибкой, не применяется ни одна.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(tx pgx.Tx, applied map[int64]time.Time) error {
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if _, err := tx.Exec(ctx, mig.Up); err != nil {
+				return fmt.Errorf("миграция %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, mig.Version, mig.Name); err != nil {
+				return fmt.Errorf("не удалось записать версию %d: %w", mig.Version, err)
+			}
+			done = append(done, mig)
+		}
+		m.warnUnknown(applied)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return done, nil
+}
+
+// Down откатывает steps последних примененных миграций и возвращает их
+// в порядке отката.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("число откатываемых миграций должно быть положительным")
+	}
+	var done []Migration
+	err := m.locked(ctx, func(tx pgx.Tx, applied map[int64]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if _, err := tx.Exec(ctx, mig.Down); err != nil {
+				return fmt.Errorf("откат миграции %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version); err != nil {
+				return fmt.Errorf("не удалось удалить версию %d: %w", mig.Version, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return done, nil
+}
+
+// Status возвращает все известные миграции с отметкой о применении.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.locked(ctx, func(_ pgx.Tx, applied map[int64]time.Time) error {
+		for _, mig := range m.migrations {
+			statuses = append(statuses, Status{Migration: mig, AppliedAt: applied[mig.Version]})
+		}
+		m.warnUnknown(applied)
+		return nil
+	})
+	return statuses, err
+}
+
+// locked выполняет fn в транзакции под advisory-блокировкой, передавая ей
+// примененные версии. Транзакция фиксируется, только если fn завершилась без ошибки.
+func (m *Migrator) locked(ctx context.Context, fn func(tx pgx.Tx, applied map[int64]time.Time) error) error {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию миграций: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }() // после Commit ничего не делает
+
+	// Блокировка снимается при завершении транзакции
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("не удалось получить блокировку миграций: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+	)`); err != nil {
+		return fmt.Errorf("не удалось создать таблицу schema_migrations: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать примененные миграции: %w", err)
+	}
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			rows.Close()
+			return fmt.Errorf("не удалось прочитать примененные миграции: %w", err)
+		}
+		applied[version] = at
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("не удалось прочитать примененные миграции: %w", err)
+	}
+
+	if err := fn(tx, applied); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("не удалось зафиксировать миграции: %w", err)
+	}
+	return nil
+}
+
+// warnUnknown сообщает о версиях в БД, которых нет в бинарнике: так бывает, если
+// после выкладки новой версии запущена старая.
+func (m *Migrator) warnUnknown(applied map[int64]time.Time) {
+	for version := range applied {
+		if !slices.ContainsFunc(m.migrations, func(mig Migration) bool { return mig.Version == version }) {
+			log.Printf("В БД применена неизвестная миграция %d: схема новее, чем ожидает эта версия сервиса", version)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "first", Up: "CREATE TABLE first (id INT)", Down: "DROP TABLE first"},
+	{Version: 2, Name: "second", Up: "CREATE TABLE second (id INT)", Down: "DROP TABLE second"},
+}
+
+// expectLocked ожидает начало транзакции миграций: блокировку, создание
+// schema_migrations и чтение примененных версий.
+func expectLocked(mock pgxmock.PgxPoolIface, applied ...int64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock($1)`)).
+		WithArgs(lockKey).
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).
+		WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	rows := pgxmock.NewRows([]string{"version", "applied_at"})
+	for _, v := range applied {
+		rows.AddRow(v, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+	mock.ExpectQuery(`SELECT version, applied_at FROM schema_migrations`).WillReturnRows(rows)
+}
+
+// TestNew_EmbeddedMigrations проверяет, что встроенные миграции читаются и идут по порядку
+func TestNew_EmbeddedMigrations(t *testing.T) {
+	m, err := New(nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, m.migrations)
+	for i, mig := range m.migrations {
+		assert.Equal(t, int64(i+1), mig.Version, "версии должны идти подряд")
+		assert.NotEmpty(t, mig.Up)
+		assert.NotEmpty(t, mig.Down)
+	}
+}
+
+// TestLoad_Errors проверяет отказ при некорректном наборе файлов
+func TestLoad_Errors(t *testing.T) {
+	sql := &fstest.MapFile{Data: []byte("SELECT 1")}
+	tests := map[string]fstest.MapFS{
+		"нет файла down":      {"0001_init.up.sql": sql},
+		"неверное имя":        {"init.up.sql": sql},
+		"две миграции версии": {"0001_a.up.sql": sql, "0001_a.down.sql": sql, "0001_b.up.sql": sql, "0001_b.down.sql": sql},
+	}
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestLoad_Sorted проверяет порядок версий независимо от порядка файлов
+func TestLoad_Sorted(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_later.up.sql":   {Data: []byte("up 10")},
+		"0010_later.down.sql": {Data: []byte("down 10")},
+		"0002_early.up.sql":   {Data: []byte("up 2")},
+		"0002_early.down.sql": {Data: []byte("down 2")},
+		"README.md":           {Data: []byte("не миграция")},
+	}
+
+	migrations, err := Load(fsys)
+
+	require.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 2, Name: "early", Up: "up 2", Down: "down 2"},
+		{Version: 10, Name: "later", Up: "up 10", Down: "down 10"},
+	}, migrations)
+}
+
+// TestMigrator_Up применяет только миграции, которых нет в schema_migrations
+func TestMigrator_Up(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	// 1. Первая миграция уже применена
+	expectLocked(mock, 1)
+	// 2. Вторая применяется и записывается в той же транзакции
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE second (id INT)`)).
+		WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`)).
+		WithArgs(int64(2), "second").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	applied, err := NewWithMigrations(mock, testMigrations).Up(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, int64(2), applied[0].Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMigrator_Up_Failure откатывает все изменения запуска, если миграция не применилась
+func TestMigrator_Up_Failure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	expectLocked(mock)
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE first (id INT)`)).
+		WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations`).
+		WithArgs(int64(1), "first").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE second (id INT)`)).
+		WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+
+	applied, err := NewWithMigrations(mock, testMigrations).Up(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2_second")
+	assert.Empty(t, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMigrator_Down откатывает последние примененные миграции в обратном порядке
+func TestMigrator_Down(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	expectLocked(mock, 1, 2)
+	mock.ExpectExec(regexp.QuoteMeta(`DROP TABLE second`)).
+		WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM schema_migrations WHERE version = $1`)).
+		WithArgs(int64(2)).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectCommit()
+
+	rolledBack, err := NewWithMigrations(mock, testMigrations).Down(context.Background(), 1)
+
+	require.NoError(t, err)
+	require.Len(t, rolledBack, 1)
+	assert.Equal(t, "second", rolledBack[0].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMigrator_Status отмечает примененные миграции
+func TestMigrator_Status(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	expectLocked(mock, 1)
+	mock.ExpectCommit()
+
+	statuses, err := NewWithMigrations(mock, testMigrations).Status(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Applied())
+	assert.False(t, statuses[1].Applied())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,5 +1,6 @@
--- Отключаем проверки внешних ключей на время создания таблиц для удобства
-SET session_replication_role = 'replica';
+-- Исходная схема (раньше создавалась скриптом sql/init.sql при первом запуске контейнера).
+-- Все объекты создаются с IF NOT EXISTS, чтобы базы, созданные тем скриптом,
+-- переходили на миграции без пересоздания.
 
 -- Таблица для хранения информации о доставке
 CREATE TABLE IF NOT EXISTS delivery (
@@ -27,23 +28,6 @@ CREATE TABLE IF NOT EXISTS payment (
     custom_fee INT
     );
 
--- Таблица для хранения товаров в заказе
-CREATE TABLE IF NOT EXISTS items (
-    id SERIAL PRIMARY KEY, -- собственный первичный ключ для удобства
-    order_uid VARCHAR(255) NOT NULL,
-    chrt_id INT NOT NULL,
-    track_number VARCHAR(255) NOT NULL,
-    price INT NOT NULL,
-    rid VARCHAR(255) NOT NULL,
-    name VARCHAR(255) NOT NULL,
-    sale INT,
-    size VARCHAR(10),
-    total_price INT NOT NULL,
-    nm_id INT NOT NULL,
-    brand VARCHAR(100),
-    status INT
-    );
-
 -- Основная таблица заказов
 CREATE TABLE IF NOT EXISTS orders (
     order_uid VARCHAR(255) PRIMARY KEY,
@@ -57,18 +41,33 @@ CREATE TABLE IF NOT EXISTS orders (
     oof_shard VARCHAR(10),
     locale VARCHAR(10),
     internal_signature VARCHAR(255),
+    payload_hash VARCHAR(64), -- хэш содержимого заказа для распознавания повторных доставок
     -- Связываем с другими таблицами через внешние ключи
     CONSTRAINT fk_delivery FOREIGN KEY (order_uid) REFERENCES delivery(order_uid) ON DELETE CASCADE,
     CONSTRAINT fk_payment FOREIGN KEY (order_uid) REFERENCES payment(transaction_id) ON DELETE CASCADE
     );
 
--- Добавляем внешний ключ для items после создания orders
-ALTER TABLE items
-    ADD CONSTRAINT fk_items_order FOREIGN KEY (order_uid) REFERENCES orders(order_uid) ON DELETE CASCADE;
+-- В базах, созданных исходным скриптом до появления хэша, таблица orders уже
+-- существует без этой колонки, и CREATE TABLE выше ее не добавит
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payload_hash VARCHAR(64);
+
+-- Таблица для хранения товаров в заказе
+CREATE TABLE IF NOT EXISTS items (
+    id SERIAL PRIMARY KEY, -- собственный первичный ключ для удобства
+    order_uid VARCHAR(255) NOT NULL,
+    chrt_id INT NOT NULL,
+    track_number VARCHAR(255) NOT NULL,
+    price INT NOT NULL,
+    rid VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    sale INT,
+    size VARCHAR(10),
+    total_price INT NOT NULL,
+    nm_id INT NOT NULL,
+    brand VARCHAR(100),
+    status INT,
+    CONSTRAINT fk_items_order FOREIGN KEY (order_uid) REFERENCES orders(order_uid) ON DELETE CASCADE
+    );
 
--- Создаем индексы для ускорения поиска
 CREATE INDEX IF NOT EXISTS idx_items_order_uid ON items(order_uid);
 CREATE UNIQUE INDEX IF NOT EXISTS idx_items_order_uid_chrt_id ON items(order_uid, chrt_id);
-
--- Включаем проверки обратно
-SET session_replication_role = 'origin';
\ No newline at end of file
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_orders_date_created;
+DROP INDEX IF EXISTS idx_orders_customer_id;
+DROP INDEX IF EXISTS idx_orders_track_number;
+DROP INDEX IF EXISTS idx_orders_delivery_service;
+DROP INDEX IF EXISTS idx_payment_amount;
+DROP INDEX IF EXISTS idx_payment_bank;
+DROP INDEX IF EXISTS idx_payment_currency;
+DROP INDEX IF EXISTS idx_items_brand;
+DROP INDEX IF EXISTS idx_items_nm_id;
@@ -0,0 +1,15 @@
+-- Индексы для поиска заказов (GET /orders). Составные индексы заканчиваются
+-- ключом сортировки и order_uid, чтобы пагинация по курсору шла по индексу
+CREATE INDEX IF NOT EXISTS idx_orders_date_created ON orders(date_created, order_uid);
+-- Также используются GET /customers/{customer_id}/orders и GET /track/{track_number}
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON orders(customer_id, date_created, order_uid);
+-- Прежний скрипт создавал индекс с тем же именем только по track_number;
+-- IF NOT EXISTS оставил бы его, поэтому индекс пересоздается
+DROP INDEX IF EXISTS idx_orders_track_number;
+CREATE INDEX idx_orders_track_number ON orders(track_number, date_created);
+CREATE INDEX IF NOT EXISTS idx_orders_delivery_service ON orders(delivery_service, date_created, order_uid);
+CREATE INDEX IF NOT EXISTS idx_payment_amount ON payment(amount, transaction_id);
+CREATE INDEX IF NOT EXISTS idx_payment_bank ON payment(bank);
+CREATE INDEX IF NOT EXISTS idx_payment_currency ON payment(currency);
+CREATE INDEX IF NOT EXISTS idx_items_brand ON items(brand, order_uid);
+CREATE INDEX IF NOT EXISTS idx_items_nm_id ON items(nm_id, order_uid);
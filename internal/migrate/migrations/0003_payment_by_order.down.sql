@@ -0,0 +1,26 @@
+-- Откат теряет данные: в старой схеме у заказа одна оплата с transaction_id = order_uid,
+-- поэтому остаются только текущие оплаты, а их transaction_id заменяется на order_uid.
+DROP INDEX IF EXISTS idx_payment_amount;
+DROP INDEX IF EXISTS idx_payment_bank;
+DROP INDEX IF EXISTS idx_payment_currency;
+DROP INDEX IF EXISTS idx_payment_current;
+
+DELETE FROM payment WHERE NOT is_current;
+UPDATE payment SET transaction_id = order_uid;
+
+ALTER TABLE payment
+    DROP CONSTRAINT fk_payment_order,
+    DROP CONSTRAINT payment_order_transaction_key,
+    DROP CONSTRAINT payment_pkey,
+    ADD PRIMARY KEY (transaction_id),
+    DROP COLUMN id,
+    DROP COLUMN order_uid,
+    DROP COLUMN is_current,
+    DROP COLUMN created_at;
+
+ALTER TABLE orders
+    ADD CONSTRAINT fk_payment FOREIGN KEY (order_uid) REFERENCES payment(transaction_id) ON DELETE CASCADE;
+
+CREATE INDEX idx_payment_amount ON payment(amount, transaction_id);
+CREATE INDEX idx_payment_bank ON payment(bank);
+CREATE INDEX idx_payment_currency ON payment(currency);
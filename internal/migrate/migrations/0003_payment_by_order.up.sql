@@ -0,0 +1,35 @@
+-- Оплата привязывается к заказу явной колонкой order_uid, а не через совпадение
+-- transaction_id с order_uid. У заказа может быть несколько попыток оплаты (по одной
+-- на транзакцию); текущая — последняя сохраненная, она отмечена is_current.
+
+-- Раньше orders ссылалась на payment(transaction_id); теперь ссылка обратная
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS fk_payment;
+
+ALTER TABLE payment
+    ADD COLUMN id BIGSERIAL,
+    ADD COLUMN order_uid VARCHAR(255),
+    ADD COLUMN is_current BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now();
+
+-- В старой схеме оплата заказа хранилась с transaction_id = order_uid. Оплаты без
+-- заказа прочитать было нельзя, поэтому они удаляются
+DELETE FROM payment p WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.order_uid = p.transaction_id);
+UPDATE payment SET order_uid = transaction_id;
+
+ALTER TABLE payment
+    ALTER COLUMN order_uid SET NOT NULL,
+    DROP CONSTRAINT payment_pkey,
+    ADD PRIMARY KEY (id),
+    ADD CONSTRAINT payment_order_transaction_key UNIQUE (order_uid, transaction_id),
+    ADD CONSTRAINT fk_payment_order FOREIGN KEY (order_uid) REFERENCES orders(order_uid) ON DELETE CASCADE;
+
+-- Ровно одна текущая оплата на заказ
+CREATE UNIQUE INDEX idx_payment_current ON payment(order_uid) WHERE is_current;
+
+-- Поиск по сумме, банку и валюте идет по текущим оплатам
+DROP INDEX IF EXISTS idx_payment_amount;
+DROP INDEX IF EXISTS idx_payment_bank;
+DROP INDEX IF EXISTS idx_payment_currency;
+CREATE INDEX idx_payment_amount ON payment(amount, order_uid) WHERE is_current;
+CREATE INDEX idx_payment_bank ON payment(bank) WHERE is_current;
+CREATE INDEX idx_payment_currency ON payment(currency) WHERE is_current;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_delivery_phone_e164;
+ALTER TABLE delivery DROP COLUMN IF EXISTS phone_e164;
+ALTER TABLE delivery DROP COLUMN IF EXISTS country;
@@ -0,0 +1,12 @@
+-- Страна доставки (ISO 3166-1 alpha-2) и телефон в формате E.164, по которому
+-- поддержка ищет заказы. phone_e164 пустой, если номер не удалось нормализовать.
+ALTER TABLE delivery ADD COLUMN IF NOT EXISTS country VARCHAR(2) NOT NULL DEFAULT '';
+ALTER TABLE delivery ADD COLUMN IF NOT EXISTS phone_e164 VARCHAR(16);
+
+-- Номера, уже записанные в международном формате, переносятся без разделителей;
+-- остальные нормализуются при следующей доставке заказа
+UPDATE delivery
+SET phone_e164 = '+' || regexp_replace(phone, '[^0-9]', '', 'g')
+WHERE phone LIKE '+%' AND length(regexp_replace(phone, '[^0-9]', '', 'g')) BETWEEN 8 AND 15;
+
+CREATE INDEX IF NOT EXISTS idx_delivery_phone_e164 ON delivery(phone_e164);
@@ -28,6 +28,8 @@ type Delivery struct {
 	Address string `json:"address"`
 	Region  string `json:"region"`
 	Email   string `json:"email"`
+	// Country — страна доставки (ISO 3166-1 alpha-2); если не задана, определяется по локали заказа
+	Country string `json:"country,omitempty"`
 }
 
 type Payment struct {
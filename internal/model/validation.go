@@ -11,7 +11,11 @@ var ( // Регулярное выражение для проверки email
 	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 )
 
-// Validate проверяет корректность данных в OrderData.
+// Validate проверяет корректность данных в OrderData по фиксированным правилам
+// и возвращает первое найденное нарушение.
+//
+// Deprecated: используйте validation.Default().Validate или набор правил из
+// конфигурации: они настраиваются и сообщают сразу обо всех нарушениях.
 func (o *OrderData) Validate() error {
 	if o.OrderUID == "" {
 		return errors.New("OrderUID не может быть пустым")
@@ -65,6 +69,9 @@ func (o *OrderData) Validate() error {
 }
 
 // Validate проверяет корректность данных в Delivery.
+//
+// Deprecated: используйте validation.Default().ValidateDelivery; телефон и индекс
+// с учетом страны доставки проверяет validation.Contacts.
 func (d *Delivery) Validate() error {
 	if d.Name == "" {
 		return errors.New("Delivery.Name не может быть пустым")
@@ -94,6 +101,8 @@ func (d *Delivery) Validate() error {
 }
 
 // Validate проверяет корректность данных в Payment.
+//
+// Deprecated: используйте validation.Default().ValidatePayment.
 func (p *Payment) Validate() error {
 	if p.Transaction == "" {
 		return errors.New("Payment.Transaction не может быть пустым")
@@ -140,6 +149,8 @@ func isValidCurrency(currency string) bool {
 }
 
 // Validate проверяет корректность данных в Item.
+//
+// Deprecated: используйте validation.Default().ValidateItem.
 func (i *Item) Validate() error {
 	if i.ChrtID <= 0 {
 		return errors.New("Item.ChrtID должен быть положительным числом")
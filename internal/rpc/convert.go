@@ -0,0 +1,118 @@
+package rpc
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"l1/internal/database"
+	"l1/internal/model"
+	"l1/internal/rpc/orderspb"
+	"l1/internal/validation"
+)
+
+// toProtoOrder переводит заказ в сообщение gRPC.
+func toProtoOrder(o *model.OrderData) *orderspb.Order {
+	pb := &orderspb.Order{
+		OrderUid:          o.OrderUID,
+		TrackNumber:       o.TrackNumber,
+		Entry:             o.Entry,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSignature,
+		CustomerId:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		Shardkey:          o.Shardkey,
+		SmId:              int64(o.SmID),
+		DateCreated:       timestamppb.New(o.DateCreated),
+		OofShard:          o.OofShard,
+		Delivery: &orderspb.Delivery{
+			Name:    o.Delivery.Name,
+			Phone:   o.Delivery.Phone,
+			Zip:     o.Delivery.Zip,
+			City:    o.Delivery.City,
+			Address: o.Delivery.Address,
+			Region:  o.Delivery.Region,
+			Email:   o.Delivery.Email,
+			Country: o.Delivery.Country,
+		},
+		Payment: &orderspb.Payment{
+			Transaction:  o.Payment.Transaction,
+			RequestId:    o.Payment.RequestID,
+			Currency:     o.Payment.Currency,
+			Provider:     o.Payment.Provider,
+			Amount:       int64(o.Payment.Amount),
+			PaymentDt:    o.Payment.PaymentDt,
+			Bank:         o.Payment.Bank,
+			DeliveryCost: int64(o.Payment.DeliveryCost),
+			GoodsTotal:   int64(o.Payment.GoodsTotal),
+			CustomFee:    int64(o.Payment.CustomFee),
+		},
+	}
+	for _, item := range o.Items {
+		pb.Items = append(pb.Items, &orderspb.Item{
+			ChrtId:      int64(item.ChrtID),
+			TrackNumber: item.TrackNumber,
+			Price:       int64(item.Price),
+			Rid:         item.Rid,
+			Name:        item.Name,
+			Sale:        int64(item.Sale),
+			Size:        item.Size,
+			TotalPrice:  int64(item.TotalPrice),
+			NmId:        int64(item.NmID),
+			Brand:       item.Brand,
+			Status:      int64(item.Status),
+		})
+	}
+	return pb
+}
+
+// protoSorts сопоставляет сортировки gRPC и хранилища.
+var protoSorts = map[orderspb.OrderSort]database.OrderSort{
+	orderspb.OrderSort_ORDER_SORT_DATE_DESC:   database.SortDateDesc,
+	orderspb.OrderSort_ORDER_SORT_DATE_ASC:    database.SortDateAsc,
+	orderspb.OrderSort_ORDER_SORT_AMOUNT_DESC: database.SortAmountDesc,
+	orderspb.OrderSort_ORDER_SORT_AMOUNT_ASC:  database.SortAmountAsc,
+}
+
+// fromProtoListRequest переводит запрос gRPC в запрос к хранилищу.
+// Неизвестная сортировка передается как есть, чтобы хранилище вернуло ErrInvalidQuery.
+// Телефон приводится к E.164, в котором он хранится; если это невозможно,
+// возвращается ErrInvalidQuery.
+func fromProtoListRequest(req *orderspb.ListOrdersRequest) (database.ListOrdersQuery, error) {
+	q := database.ListOrdersQuery{
+		Limit:  int(req.GetLimit()),
+		Cursor: req.GetCursor(),
+	}
+	if req.GetSort() != orderspb.OrderSort_ORDER_SORT_UNSPECIFIED {
+		sort, ok := protoSorts[req.GetSort()]
+		if !ok {
+			sort = database.OrderSort(req.GetSort().String())
+		}
+		q.Sort = sort
+	}
+
+	f := req.GetFilter()
+	q.Filter = database.OrderFilter{
+		CustomerID:      f.GetCustomerId(),
+		TrackNumber:     f.GetTrackNumber(),
+		DeliveryService: f.GetDeliveryService(),
+		Bank:            f.GetBank(),
+		Currency:        f.GetCurrency(),
+		Brand:           f.GetBrand(),
+		NmID:            int(f.GetNmId()),
+	}
+	if f.GetCreatedFrom() != nil {
+		q.Filter.CreatedFrom = f.GetCreatedFrom().AsTime()
+	}
+	if f.GetCreatedTo() != nil {
+		q.Filter.CreatedTo = f.GetCreatedTo().AsTime()
+	}
+	if f.GetPhone() != "" {
+		phone, err := validation.NormalizePhone(f.GetPhone(), "")
+		if err != nil {
+			return q, fmt.Errorf("%w: телефон должен быть номером в международном формате: %v", database.ErrInvalidQuery, err)
+		}
+		q.Filter.Phone = phone
+	}
+	return q, nil
+}
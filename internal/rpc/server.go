@@ -0,0 +1,220 @@
+// Package rpc — gRPC-сервер заказов (см. orderspb/orders.proto).
+package rpc
+
+//go:generate protoc -I . --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative orderspb/orders.proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"l1/internal/database"
+	"l1/internal/health"
+	"l1/internal/metrics"
+	"l1/internal/rpc/orderspb"
+	"l1/internal/server"
+)
+
+// OrderStore — хранилище, поверх которого работает gRPC-сервер (реализуется database.Service).
+type OrderStore interface {
+	server.OrderGetter
+	server.OrderLister
+	Subscribe(buffer int) *database.Subscription
+}
+
+// Options — настройки gRPC-сервера. Нулевые значения заменяются значениями по умолчанию.
+type Options struct {
+	// Сколько времени есть у вызова на получение данных, если клиент не передал
+	// более короткий дедлайн
+	RequestTimeout time.Duration
+	// Сколько событий WatchOrders может ждать отправки клиенту; при переполнении
+	// новые события для этого клиента пропускаются
+	WatchBuffer int
+}
+
+func (o Options) withDefaults() Options {
+	if o.RequestTimeout <= 0 {
+		o.RequestTimeout = 3 * time.Second
+	}
+	if o.WatchBuffer <= 0 {
+		o.WatchBuffer = 64
+	}
+	return o
+}
+
+// Server — gRPC-сервер OrderService.
+type Server struct {
+	orderspb.UnimplementedOrderServiceServer
+
+	store     OrderStore
+	opts      Options
+	component *health.Component
+	grpc      *grpc.Server
+	stopping  chan struct{} // закрывается при остановке, чтобы завершить потоки WatchOrders
+	stopOnce  sync.Once
+}
+
+// New создает сервер. Состояние сервера сообщается в h под именем "grpc"; h может быть nil.
+func New(store OrderStore, h *health.Registry, opts Options) *Server {
+	s := &Server{
+		store:    store,
+		opts:     opts.withDefaults(),
+		stopping: make(chan struct{}),
+	}
+	if h != nil {
+		s.component = h.Component("grpc")
+	}
+	s.grpc = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryMetrics),
+		grpc.ChainStreamInterceptor(streamMetrics),
+	)
+	orderspb.RegisterOrderServiceServer(s.grpc, s)
+	return s
+}
+
+// Start запускает сервер и блокируется до его остановки. После Shutdown возвращает nil.
+func (s *Server) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		s.component.SetError(err)
+		return fmt.Errorf("не удалось открыть порт %s: %w", addr, err)
+	}
+	log.Printf("gRPC-сервер запущен на %s", addr)
+	return s.Serve(ln)
+}
+
+// Serve обслуживает соединения из ln до остановки сервера. После Shutdown возвращает nil.
+func (s *Server) Serve(ln net.Listener) error {
+	s.component.SetReady()
+	if err := s.grpc.Serve(ln); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		s.component.SetError(err)
+		return fmt.Errorf("ошибка gRPC-сервера: %w", err)
+	}
+	return nil
+}
+
+// Shutdown завершает потоки WatchOrders, перестает принимать вызовы и дожидается
+// текущих. Если ctx отменен раньше, оставшиеся вызовы прерываются.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.component.SetError(errors.New("сервер останавливается"))
+	s.stopOnce.Do(func() { close(s.stopping) })
+
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		log.Println("gRPC-сервер остановлен")
+		return nil
+	case <-ctx.Done():
+		s.grpc.Stop()
+		return fmt.Errorf("не удалось дождаться завершения gRPC-вызовов: %w", ctx.Err())
+	}
+}
+
+// GetOrder возвращает заказ по UID.
+func (s *Server) GetOrder(ctx context.Context, req *orderspb.GetOrderRequest) (*orderspb.Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.RequestTimeout)
+	defer cancel()
+
+	order, err := s.store.GetOrderByUID(ctx, req.GetOrderUid())
+	if err != nil {
+		log.Printf("gRPC: ошибка получения заказа %s: %v", req.GetOrderUid(), err)
+		return nil, toStatus(err)
+	}
+	return toProtoOrder(order), nil
+}
+
+// ListOrders ищет заказы по фильтру.
+func (s *Server) ListOrders(ctx context.Context, req *orderspb.ListOrdersRequest) (*orderspb.ListOrdersResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.RequestTimeout)
+	defer cancel()
+
+	q, err := fromProtoListRequest(req)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	page, err := s.store.ListOrders(ctx, q)
+	if err != nil {
+		log.Printf("gRPC: ошибка поиска заказов: %v", err)
+		return nil, toStatus(err)
+	}
+
+	resp := &orderspb.ListOrdersResponse{NextCursor: page.NextCursor}
+	for _, order := range page.Orders {
+		resp.Orders = append(resp.Orders, toProtoOrder(order))
+	}
+	return resp, nil
+}
+
+// WatchOrders отправляет клиенту заказы по мере их сохранения.
+func (s *Server) WatchOrders(_ *orderspb.WatchOrdersRequest, stream grpc.ServerStreamingServer[orderspb.Order]) error {
+	sub := s.store.Subscribe(s.opts.WatchBuffer)
+	defer func() {
+		sub.Close()
+		if dropped := sub.Dropped(); dropped > 0 {
+			log.Printf("gRPC: клиент WatchOrders не успевал читать, пропущено событий: %d", dropped)
+		}
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return toStatus(stream.Context().Err())
+		case <-s.stopping:
+			return status.Error(codes.Unavailable, "сервер останавливается")
+		case ev, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.Unavailable, "сервис заказов остановлен")
+			}
+			if err := stream.Send(toProtoOrder(ev.Order)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toStatus переводит ошибку хранилища в статус gRPC.
+func toStatus(err error) error {
+	var code codes.Code
+	switch {
+	case errors.Is(err, database.ErrInvalidUID), errors.Is(err, database.ErrInvalidQuery):
+		code = codes.InvalidArgument
+	case errors.Is(err, database.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, database.ErrUnavailable):
+		code = codes.Unavailable
+	default:
+		// Подробности внутренних ошибок клиенту не передаются
+		return status.Error(codes.Internal, "внутренняя ошибка сервера")
+	}
+	return status.Error(code, err.Error())
+}
+
+func unaryMetrics(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	metrics.ObserveSince(metrics.GRPCDuration.With(info.FullMethod, status.Code(err).String()), start)
+	return resp, err
+}
+
+func streamMetrics(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	metrics.ObserveSince(metrics.GRPCDuration.With(info.FullMethod, status.Code(err).String()), start)
+	return err
+}
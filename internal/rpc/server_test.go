@@ -0,0 +1,238 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"l1/internal/database"
+	"l1/internal/model"
+	"l1/internal/rpc/orderspb"
+)
+
+// --- Мок ---
+
+// MockOrderStore — мок для интерфейса OrderStore. События публикуются через events.
+type MockOrderStore struct {
+	mock.Mock
+	events *database.Broadcaster
+}
+
+func (m *MockOrderStore) GetOrderByUID(ctx context.Context, orderUID string) (*model.OrderData, error) {
+	args := m.Called(ctx, orderUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.OrderData), args.Error(1)
+}
+
+func (m *MockOrderStore) ListOrders(ctx context.Context, q database.ListOrdersQuery) (*database.OrderPage, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrderPage), args.Error(1)
+}
+
+func (m *MockOrderStore) Subscribe(buffer int) *database.Subscription {
+	return m.events.Subscribe(buffer)
+}
+
+// startServer запускает сервер на bufconn и возвращает клиента к нему.
+func startServer(t *testing.T, store *MockOrderStore) (*Server, orderspb.OrderServiceClient) {
+	t.Helper()
+	ln := bufconn.Listen(1 << 20)
+	srv := New(store, nil, Options{})
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return srv, orderspb.NewOrderServiceClient(conn)
+}
+
+func newStore() *MockOrderStore {
+	return &MockOrderStore{events: database.NewBroadcaster()}
+}
+
+// --- Тесты ---
+
+// TestGetOrder_Success - тест получения заказа
+func TestGetOrder_Success(t *testing.T) {
+	// --- Arrange ---
+	store := newStore()
+	created := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+	order := &model.OrderData{
+		OrderUID: "uid-1", TrackNumber: "WBILMTESTTRACK", DateCreated: created,
+		Delivery: model.Delivery{Phone: "+79161234567", Country: "RU"},
+		Payment:  model.Payment{Amount: 1817, Currency: "USD"},
+		Items:    []model.Item{{ChrtID: 9934930, NmID: 2389212}},
+	}
+	store.On("GetOrderByUID", mock.Anything, "uid-1").Return(order, nil).Once()
+	_, client := startServer(t, store)
+
+	// --- Act ---
+	got, err := client.GetOrder(context.Background(), &orderspb.GetOrderRequest{OrderUid: "uid-1"})
+
+	// --- Assert ---
+	require.NoError(t, err)
+	assert.Equal(t, "uid-1", got.GetOrderUid())
+	assert.Equal(t, "WBILMTESTTRACK", got.GetTrackNumber())
+	assert.Equal(t, int64(1817), got.GetPayment().GetAmount())
+	assert.Equal(t, "RU", got.GetDelivery().GetCountry())
+	assert.True(t, created.Equal(got.GetDateCreated().AsTime()))
+	require.Len(t, got.GetItems(), 1)
+	assert.Equal(t, int64(2389212), got.GetItems()[0].GetNmId())
+	store.AssertExpectations(t)
+}
+
+// TestGetOrder_ErrorCodes - тест соответствия ошибок хранилища кодам gRPC
+func TestGetOrder_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"не найден", database.ErrNotFound, codes.NotFound},
+		{"некорректный UID", database.ErrInvalidUID, codes.InvalidArgument},
+		{"хранилище недоступно", database.ErrUnavailable, codes.Unavailable},
+		{"таймаут", context.DeadlineExceeded, codes.DeadlineExceeded},
+		{"внутренняя ошибка", errors.New("boom"), codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newStore()
+			store.On("GetOrderByUID", mock.Anything, "uid-1").Return(nil, tt.err).Once()
+			_, client := startServer(t, store)
+
+			_, err := client.GetOrder(context.Background(), &orderspb.GetOrderRequest{OrderUid: "uid-1"})
+
+			assert.Equal(t, tt.code, status.Code(err))
+			assert.NotContains(t, status.Convert(err).Message(), "boom", "подробности внутренних ошибок не передаются")
+		})
+	}
+}
+
+// TestListOrders - тест перевода фильтра и страницы
+func TestListOrders(t *testing.T) {
+	// --- Arrange ---
+	store := newStore()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := database.ListOrdersQuery{
+		Filter: database.OrderFilter{CustomerID: "test", CreatedFrom: from, NmID: 2389212, Phone: "+79161234567"},
+		Sort:   database.SortAmountAsc,
+		Limit:  10,
+		Cursor: "abc",
+	}
+	page := &database.OrderPage{Orders: []*model.OrderData{{OrderUID: "uid-1"}, {OrderUID: "uid-2"}}, NextCursor: "next"}
+	store.On("ListOrders", mock.Anything, want).Return(page, nil).Once()
+	_, client := startServer(t, store)
+
+	// --- Act ---
+	resp, err := client.ListOrders(context.Background(), &orderspb.ListOrdersRequest{
+		Filter: &orderspb.OrderFilter{CustomerId: "test", CreatedFrom: timestamppb.New(from), NmId: 2389212, Phone: "+7 (916) 123-45-67"},
+		Sort:   orderspb.OrderSort_ORDER_SORT_AMOUNT_ASC,
+		Limit:  10,
+		Cursor: "abc",
+	})
+
+	// --- Assert ---
+	require.NoError(t, err)
+	require.Len(t, resp.GetOrders(), 2)
+	assert.Equal(t, "uid-2", resp.GetOrders()[1].GetOrderUid())
+	assert.Equal(t, "next", resp.GetNextCursor())
+	store.AssertExpectations(t)
+}
+
+// TestListOrders_InvalidPhone - телефон без кода страны отклоняется до запроса к хранилищу
+func TestListOrders_InvalidPhone(t *testing.T) {
+	store := newStore()
+	_, client := startServer(t, store)
+
+	_, err := client.ListOrders(context.Background(), &orderspb.ListOrdersRequest{
+		Filter: &orderspb.OrderFilter{Phone: "89161234567"},
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	store.AssertNotCalled(t, "ListOrders", mock.Anything, mock.Anything)
+}
+
+// TestWatchOrders - тест потока сохраненных заказов
+func TestWatchOrders(t *testing.T) {
+	// --- Arrange ---
+	store := newStore()
+	_, client := startServer(t, store)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchOrders(ctx, &orderspb.WatchOrdersRequest{})
+	require.NoError(t, err)
+
+	// Подписка создается при обработке вызова, поэтому публикуем, пока событие не дойдет
+	received := make(chan *orderspb.Order, 1)
+	go func() {
+		order, err := stream.Recv()
+		if err == nil {
+			received <- order
+		}
+	}()
+
+	// --- Act ---
+	var got *orderspb.Order
+	for got == nil {
+		store.events.Publish(database.OrderEvent{Order: &model.OrderData{OrderUID: "uid-new"}})
+		select {
+		case got = <-received:
+		case <-time.After(20 * time.Millisecond):
+		case <-ctx.Done():
+			t.Fatal("событие не получено")
+		}
+	}
+
+	// --- Assert ---
+	assert.Equal(t, "uid-new", got.GetOrderUid())
+}
+
+// TestShutdown_EndsWatchStreams - тест завершения потоков WatchOrders при остановке
+func TestShutdown_EndsWatchStreams(t *testing.T) {
+	store := newStore()
+	srv, client := startServer(t, store)
+
+	stream, err := client.WatchOrders(context.Background(), &orderspb.WatchOrdersRequest{})
+	require.NoError(t, err)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := stream.Recv()
+		errCh <- err
+	}()
+	// Даем вызову дойти до сервера
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, srv.Shutdown(ctx), "остановка не должна ждать открытых потоков")
+
+	select {
+	case err := <-errCh:
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	case <-time.After(2 * time.Second):
+		t.Fatal("поток не завершился после остановки сервера")
+	}
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"l1/internal/database"
+)
+
+// Коды ошибок API — стабильные значения поля code, на которые может опираться клиент.
+const (
+	codeInvalidUID   = "invalid_uid"
+	codeInvalidQuery = "invalid_query"
+	codeNotFound     = "not_found"
+	codeNoRoute      = "route_not_found"
+	codeUnavailable  = "unavailable"
+	codeTimeout      = "timeout"
+	codeClientClosed = "client_closed"
+	codeInternal     = "internal"
+)
+
+// writeStoreError отправляет ответ на ошибку хранилища: категория ошибки
+// (см. сентинелы пакета database) определяет код ответа. ctx — контекст
+// обращения к хранилищу, по нему распознаются таймаут и отключение клиента.
+func writeStoreError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, database.ErrInvalidUID):
+		writeError(w, r, http.StatusBadRequest, codeInvalidUID, "Некорректный UID заказа")
+	case errors.Is(err, database.ErrInvalidQuery):
+		writeError(w, r, http.StatusBadRequest, codeInvalidQuery, "Некорректные параметры запроса")
+	case errors.Is(err, database.ErrNotFound):
+		writeError(w, r, http.StatusNotFound, codeNotFound, "Заказ не найден")
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		writeError(w, r, http.StatusGatewayTimeout, codeTimeout, "Превышено время ожидания ответа")
+	case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+		// Клиент уже не прочитает ответ, код нужен для логов и метрик
+		writeError(w, r, StatusClientClosedRequest, codeClientClosed, "Клиент закрыл соединение")
+	case errors.Is(err, database.ErrUnavailable):
+		writeError(w, r, http.StatusServiceUnavailable, codeUnavailable, "Хранилище заказов временно недоступно")
+	default:
+		writeError(w, r, http.StatusInternalServerError, codeInternal, "Внутренняя ошибка сервера")
+	}
+}
+
+// handleAPINotFound отвечает на запросы к неизвестным адресам API.
+func handleAPINotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, codeNoRoute, "Метод API не найден")
+}
+
+// RequestIDHeader — заголовок, в котором передается и возвращается идентификатор запроса.
+const RequestIDHeader = "X-Request-ID"
+
+// errorResponse — тело ответа с ошибкой.
+type errorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+}
+
+// writeError отправляет ошибку в формате JSON.
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	resp := errorResponse{Code: code, Message: message, RequestID: requestID(r)}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Ошибка отправки ответа: %v", err)
+	}
+}
+
+type requestIDKey struct{}
+
+// withRequestID берет идентификатор запроса из заголовка X-Request-ID или создает новый,
+// кладет его в контекст и возвращает клиенту в том же заголовке.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// requestID возвращает идентификатор запроса. Если обработчик вызван без
+// withRequestID, используется заголовок запроса.
+func requestID(r *http.Request) string {
+	if id, ok := r.Context().Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return r.Header.Get(RequestIDHeader)
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,17 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec — описание API v1 в формате OpenAPI 3.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// serveOpenAPI отдает описание API v1.
+func serveOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPISpec)
+}
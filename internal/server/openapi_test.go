@@ -0,0 +1,354 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"l1/internal/database"
+	"l1/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// apiSpec — часть документа OpenAPI, которую проверяют тесты.
+type apiSpec struct {
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths      map[string]map[string]apiOperation `json:"paths"`
+	Components struct {
+		Schemas   map[string]*apiSchema  `json:"schemas"`
+		Responses map[string]apiResponse `json:"responses"`
+	} `json:"components"`
+}
+
+type apiOperation struct {
+	Parameters []struct {
+		Name string `json:"name"`
+		In   string `json:"in"`
+	} `json:"parameters"`
+	Responses map[string]apiResponse `json:"responses"`
+}
+
+type apiResponse struct {
+	Ref     string `json:"$ref"`
+	Content map[string]struct {
+		Schema *apiSchema `json:"schema"`
+	} `json:"content"`
+}
+
+type apiSchema struct {
+	Ref                  string                `json:"$ref"`
+	Type                 string                `json:"type"`
+	Nullable             bool                  `json:"nullable"`
+	Required             []string              `json:"required"`
+	Properties           map[string]*apiSchema `json:"properties"`
+	AdditionalProperties *bool                 `json:"additionalProperties"`
+	Items                *apiSchema            `json:"items"`
+	Enum                 []any                 `json:"enum"`
+}
+
+func loadSpec(t *testing.T) *apiSpec {
+	t.Helper()
+	var spec apiSpec
+	require.NoError(t, json.Unmarshal(openAPISpec, &spec), "openapi.json должен быть валидным JSON")
+	require.NotEmpty(t, spec.Servers)
+	return &spec
+}
+
+// operation находит операцию по методу и фактическому пути запроса.
+func (s *apiSpec) operation(method, path string) (apiOperation, bool) {
+	path = strings.TrimPrefix(path, s.Servers[0].URL)
+	// Как и в ServeMux, путь без параметров важнее шаблона (/orders/stream и /orders/{order_uid})
+	if ops, ok := s.Paths[path]; ok {
+		op, ok := ops[strings.ToLower(method)]
+		return op, ok
+	}
+	for tmpl, ops := range s.Paths {
+		if matchTemplate(tmpl, path) {
+			op, ok := ops[strings.ToLower(method)]
+			return op, ok
+		}
+	}
+	return apiOperation{}, false
+}
+
+func matchTemplate(tmpl, path string) bool {
+	want, got := strings.Split(tmpl, "/"), strings.Split(path, "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if strings.HasPrefix(want[i], "{") {
+			if got[i] == "" {
+				return false
+			}
+			continue
+		}
+		if want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// responseSchema возвращает схему тела ответа с кодом status.
+func (s *apiSpec) responseSchema(op apiOperation, status int) (*apiSchema, bool) {
+	resp, ok := op.Responses[fmt.Sprint(status)]
+	if !ok {
+		return nil, false
+	}
+	if resp.Ref != "" {
+		resp = s.Components.Responses[strings.TrimPrefix(resp.Ref, "#/components/responses/")]
+	}
+	content, ok := resp.Content["application/json"]
+	return content.Schema, ok
+}
+
+func (s *apiSpec) resolve(schema *apiSchema) *apiSchema {
+	for schema.Ref != "" {
+		schema = s.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// validate проверяет значение, полученное из JSON, по схеме (поддерживается
+// подмножество OpenAPI, которое используется в openapi.json).
+func (s *apiSpec) validate(schema *apiSchema, value any, at string) error {
+	schema = s.resolve(schema)
+	if value == nil {
+		if schema.Nullable {
+			return nil
+		}
+		return fmt.Errorf("%s: null не допускается", at)
+	}
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, value) {
+		return fmt.Errorf("%s: значение %v не входит в enum", at, value)
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: ожидался объект", at)
+		}
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s: нет обязательного поля %s", at, name)
+			}
+		}
+		for name, v := range obj {
+			prop, ok := schema.Properties[name]
+			if !ok {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					return fmt.Errorf("%s: поле %s не описано в схеме", at, name)
+				}
+				continue
+			}
+			if err := s.validate(prop, v, at+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: ожидался массив", at)
+		}
+		for i, v := range arr {
+			if err := s.validate(schema.Items, v, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: ожидалась строка", at)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: ожидалось логическое значение", at)
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			return fmt.Errorf("%s: ожидалось целое число", at)
+		}
+	}
+	return nil
+}
+
+// TestOpenAPI_Served - тест отдачи описания API
+func TestOpenAPI_Served(t *testing.T) {
+	server := New(new(MockOrderGetter), nil, Options{})
+	rr := httptest.NewRecorder()
+
+	server.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, string(openAPISpec), rr.Body.String())
+}
+
+// TestOpenAPI_DescribesOrderData - тест соответствия схем документа структурам model
+func TestOpenAPI_DescribesOrderData(t *testing.T) {
+	spec := loadSpec(t)
+	tests := map[string]reflect.Type{
+		"Order":    reflect.TypeFor[model.OrderData](),
+		"Delivery": reflect.TypeFor[model.Delivery](),
+		"Payment":  reflect.TypeFor[model.Payment](),
+		"Item":     reflect.TypeFor[model.Item](),
+	}
+	for name, typ := range tests {
+		t.Run(name, func(t *testing.T) {
+			schema := spec.Components.Schemas[name]
+			require.NotNil(t, schema)
+
+			var fields []string
+			for i := range typ.NumField() {
+				fields = append(fields, strings.Split(typ.Field(i).Tag.Get("json"), ",")[0])
+			}
+			var props []string
+			for p := range schema.Properties {
+				props = append(props, p)
+			}
+			assert.ElementsMatch(t, fields, props, "свойства схемы должны совпадать с JSON-полями структуры")
+		})
+	}
+}
+
+// TestOpenAPI_Conformance - тест соответствия запросов и ответов API описанию
+func TestOpenAPI_Conformance(t *testing.T) {
+	spec := loadSpec(t)
+	order := &model.OrderData{
+		OrderUID: "uid-1", TrackNumber: "WBILMTESTTRACK", CustomerID: "test",
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		Items:       []model.Item{{ChrtID: 9934930, NmID: 2389212, Brand: "Vivienne Sabo"}},
+	}
+	page := &database.OrderPage{Orders: []*model.OrderData{order, {OrderUID: "uid-2"}}, NextCursor: "next"}
+
+	tests := []struct {
+		name   string
+		target string
+		setup  func(m *MockOrderGetter)
+		status int
+	}{
+		{name: "заказ", target: "/api/v1/orders/uid-1", status: http.StatusOK,
+			setup: func(m *MockOrderGetter) { m.On("GetOrderByUID", mock.Anything, "uid-1").Return(order, nil) }},
+		{name: "заказ не найден", target: "/api/v1/orders/uid-1", status: http.StatusNotFound,
+			setup: func(m *MockOrderGetter) {
+				m.On("GetOrderByUID", mock.Anything, "uid-1").Return(nil, database.ErrNotFound)
+			}},
+		{name: "некорректный UID", target: "/api/v1/orders/uid-1", status: http.StatusBadRequest,
+			setup: func(m *MockOrderGetter) {
+				m.On("GetOrderByUID", mock.Anything, "uid-1").Return(nil, database.ErrInvalidUID)
+			}},
+		{name: "хранилище недоступно", target: "/api/v1/orders/uid-1", status: http.StatusServiceUnavailable,
+			setup: func(m *MockOrderGetter) {
+				m.On("GetOrderByUID", mock.Anything, "uid-1").Return(nil, database.ErrUnavailable)
+			}},
+		{name: "таймаут", target: "/api/v1/orders/uid-1", status: http.StatusGatewayTimeout,
+			setup: func(m *MockOrderGetter) {
+				m.On("GetOrderByUID", mock.Anything, "uid-1").Return(nil, context.DeadlineExceeded)
+			}},
+		{name: "внутренняя ошибка", target: "/api/v1/orders/uid-1", status: http.StatusInternalServerError,
+			setup: func(m *MockOrderGetter) {
+				m.On("GetOrderByUID", mock.Anything, "uid-1").Return(nil, errors.New("boom"))
+			}},
+		{name: "поиск", target: "/api/v1/orders?customer_id=test&sort=amount_asc&limit=2&nm_id=2389212", status: http.StatusOK,
+			setup: func(m *MockOrderGetter) { m.On("ListOrders", mock.Anything, mock.Anything).Return(page, nil) }},
+		{name: "поиск с некорректной датой", target: "/api/v1/orders?created_from=вчера", status: http.StatusBadRequest},
+		{name: "лента с некорректной датой", target: "/api/v1/orders/stream?created_from=вчера", status: http.StatusBadRequest},
+		{name: "оплаты заказа", target: "/api/v1/orders/uid-1/payments", status: http.StatusOK,
+			setup: func(m *MockOrderGetter) {
+				m.On("GetPaymentAttempts", mock.Anything, "uid-1").Return([]database.PaymentAttempt{
+					{Payment: model.Payment{Transaction: "tx-1"}, CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+					{Payment: model.Payment{Transaction: "tx-2"}, Current: true, CreatedAt: time.Date(2024, 5, 1, 12, 1, 0, 0, time.UTC)},
+				}, nil)
+			}},
+		{name: "оплаты несуществующего заказа", target: "/api/v1/orders/uid-1/payments", status: http.StatusNotFound,
+			setup: func(m *MockOrderGetter) {
+				m.On("GetPaymentAttempts", mock.Anything, "uid-1").Return(nil, database.ErrNotFound)
+			}},
+		{name: "заказ по трек-номеру", target: "/api/v1/tracks/WBILMTESTTRACK", status: http.StatusOK,
+			setup: func(m *MockOrderGetter) {
+				m.On("GetOrderByTrackNumber", mock.Anything, "WBILMTESTTRACK").Return(order, nil)
+			}},
+		{name: "заказы покупателя", target: "/api/v1/customers/test/orders?cursor=abc", status: http.StatusOK,
+			setup: func(m *MockOrderGetter) {
+				m.On("GetCustomerOrders", mock.Anything, "test", mock.Anything).Return(&database.OrderPage{}, nil)
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// --- Arrange ---
+			mockStore := new(MockOrderGetter)
+			if tt.setup != nil {
+				tt.setup(mockStore)
+			}
+			server := New(mockStore, nil, Options{})
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			rr := httptest.NewRecorder()
+
+			// Запрос: операция и все параметры описаны в документе
+			op, ok := spec.operation(req.Method, req.URL.Path)
+			require.True(t, ok, "операция %s %s не описана", req.Method, req.URL.Path)
+			declared := map[string]bool{}
+			for _, p := range op.Parameters {
+				declared[p.In+":"+p.Name] = true
+			}
+			for name := range req.URL.Query() {
+				assert.True(t, declared["query:"+name], "параметр %s не описан", name)
+			}
+
+			// --- Act ---
+			server.routes().ServeHTTP(rr, req)
+
+			// --- Assert ---
+			// Ответ: код описан, тело соответствует схеме
+			require.Equal(t, tt.status, rr.Code)
+			schema, ok := spec.responseSchema(op, rr.Code)
+			require.True(t, ok, "ответ %d не описан", rr.Code)
+			var body any
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			assert.NoError(t, spec.validate(schema, body, "body"))
+		})
+	}
+}
+
+// TestAPI_UnknownRoute - тест ответа на неизвестный адрес API
+func TestAPI_UnknownRoute(t *testing.T) {
+	spec := loadSpec(t)
+	server := New(new(MockOrderGetter), nil, Options{})
+	rr := httptest.NewRecorder()
+
+	server.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/unknown", nil))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	var body any
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.NoError(t, spec.validate(&apiSchema{Ref: "#/components/schemas/Error"}, body, "body"))
+}
+
+// TestAPI_LegacyRoutes - тест адресов без версии, оставленных для совместимости
+func TestAPI_LegacyRoutes(t *testing.T) {
+	mockStore := new(MockOrderGetter)
+	mockStore.On("GetOrderByUID", mock.Anything, "uid-1").Return(&model.OrderData{OrderUID: "uid-1"}, nil).Once()
+	server := New(mockStore, nil, Options{})
+	rr := httptest.NewRecorder()
+
+	server.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/order/"+url.PathEscape("uid-1"), nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockStore.AssertExpectations(t)
+}
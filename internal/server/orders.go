@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"l1/internal/database"
+	"l1/internal/model"
+	"l1/internal/validation"
+)
+
+// OrderLister определяет интерфейс поиска заказов для GET /orders.
+type OrderLister interface {
+	ListOrders(ctx context.Context, q database.ListOrdersQuery) (*database.OrderPage, error)
+}
+
+// OrderFinder определяет интерфейс поиска заказов по трек-номеру и по покупателю.
+type OrderFinder interface {
+	GetOrderByTrackNumber(ctx context.Context, track string) (*model.OrderData, error)
+	GetCustomerOrders(ctx context.Context, customerID string, q database.ListOrdersQuery) (*database.OrderPage, error)
+}
+
+// PaymentHistory определяет интерфейс получения истории попыток оплаты заказа.
+type PaymentHistory interface {
+	GetPaymentAttempts(ctx context.Context, orderUID string) ([]database.PaymentAttempt, error)
+}
+
+// paymentListResponse — тело ответа GET /orders/{order_uid}/payments.
+type paymentListResponse struct {
+	Payments []database.PaymentAttempt `json:"payments"`
+}
+
+// orderListResponse — тело ответа GET /orders.
+type orderListResponse struct {
+	Orders     []*model.OrderData `json:"orders"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// handleListOrders ищет заказы по параметрам запроса:
+// customer_id, track_number, delivery_service, created_from, created_to (RFC 3339),
+// bank, currency, brand, nm_id, sort, limit и cursor.
+func (s *Server) handleListOrders(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, codeInvalidQuery, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	defer cancel()
+
+	page, err := s.lister.ListOrders(ctx, q)
+	if err != nil {
+		log.Printf("Ошибка поиска заказов (request_id=%s): %v", requestID(r), err)
+		writeStoreError(ctx, w, r, err)
+		return
+	}
+
+	writePage(w, page)
+}
+
+// handleGetOrderByTrack отдает заказ по трек-номеру: GET /track/{track_number}.
+func (s *Server) handleGetOrderByTrack(w http.ResponseWriter, r *http.Request) {
+	track := r.PathValue("track_number")
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	defer cancel()
+
+	order, err := s.finder.GetOrderByTrackNumber(ctx, track)
+	if err != nil {
+		log.Printf("Ошибка получения заказа по трек-номеру %s (request_id=%s): %v", track, requestID(r), err)
+		writeStoreError(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(order); err != nil {
+		log.Printf("Ошибка кодирования заказа %s: %v", order.OrderUID, err)
+	}
+}
+
+// handleCustomerOrders отдает заказы покупателя: GET /customers/{customer_id}/orders.
+// Поддерживает те же параметры фильтрации, сортировки и пагинации, что и GET /orders.
+func (s *Server) handleCustomerOrders(w http.ResponseWriter, r *http.Request) {
+	customerID := r.PathValue("customer_id")
+	q, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, codeInvalidQuery, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	defer cancel()
+
+	page, err := s.finder.GetCustomerOrders(ctx, customerID, q)
+	if err != nil {
+		log.Printf("Ошибка получения заказов покупателя %s (request_id=%s): %v", customerID, requestID(r), err)
+		writeStoreError(ctx, w, r, err)
+		return
+	}
+	writePage(w, page)
+}
+
+// handleGetPayments отдает все попытки оплаты заказа, начиная с первой:
+// GET /orders/{order_uid}/payments. Текущая попытка отмечена полем current.
+func (s *Server) handleGetPayments(w http.ResponseWriter, r *http.Request) {
+	orderUID := r.PathValue("order_uid")
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	defer cancel()
+
+	attempts, err := s.payments.GetPaymentAttempts(ctx, orderUID)
+	if err != nil {
+		log.Printf("Ошибка получения оплат заказа %s (request_id=%s): %v", orderUID, requestID(r), err)
+		writeStoreError(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(paymentListResponse{Payments: attempts}); err != nil {
+		log.Printf("Ошибка кодирования оплат заказа %s: %v", orderUID, err)
+	}
+}
+
+// writePage отправляет страницу списка заказов.
+func writePage(w http.ResponseWriter, page *database.OrderPage) {
+	resp := orderListResponse{Orders: page.Orders, NextCursor: page.NextCursor}
+	if resp.Orders == nil {
+		resp.Orders = []*model.OrderData{} // пустой список, а не null
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Ошибка кодирования списка заказов: %v", err)
+	}
+}
+
+// parseListQuery разбирает параметры запроса списка заказов.
+func parseListQuery(v url.Values) (database.ListOrdersQuery, error) {
+	q := database.ListOrdersQuery{
+		Filter: database.OrderFilter{
+			CustomerID:      v.Get("customer_id"),
+			TrackNumber:     v.Get("track_number"),
+			DeliveryService: v.Get("delivery_service"),
+			Bank:            v.Get("bank"),
+			Currency:        v.Get("currency"),
+			Brand:           v.Get("brand"),
+		},
+		Sort:   database.OrderSort(v.Get("sort")),
+		Cursor: v.Get("cursor"),
+	}
+
+	var err error
+	if q.Filter.CreatedFrom, err = parseTime(v, "created_from"); err != nil {
+		return q, err
+	}
+	if q.Filter.CreatedTo, err = parseTime(v, "created_to"); err != nil {
+		return q, err
+	}
+	if q.Filter.NmID, err = parseInt(v, "nm_id"); err != nil {
+		return q, err
+	}
+	if q.Limit, err = parseInt(v, "limit"); err != nil {
+		return q, err
+	}
+	if q.Limit < 0 {
+		return q, errors.New("параметр limit должен быть положительным")
+	}
+	if phone := v.Get("phone"); phone != "" {
+		// Неэкранированный «+» в строке запроса декодируется как пробел:
+		// ?phone=+7916... приходит как « 7916...»
+		if strings.HasPrefix(phone, " ") {
+			phone = "+" + phone[1:]
+		}
+		// Номер ищется в том виде, в котором сохраняется: в формате E.164
+		if q.Filter.Phone, err = validation.NormalizePhone(phone, ""); err != nil {
+			return q, fmt.Errorf("параметр phone должен быть номером в международном формате: %w", err)
+		}
+	}
+	return q, nil
+}
+
+func parseTime(v url.Values, name string) (time.Time, error) {
+	s := v.Get(name)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("параметр %s должен быть датой в формате RFC 3339", name)
+	}
+	return t, nil
+}
+
+func parseInt(v url.Values, name string) (int, error) {
+	s := v.Get(name)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("параметр %s должен быть целым числом", name)
+	}
+	return n, nil
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"l1/internal/database"
+	"l1/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleListOrders_Success - тест разбора параметров и ответа со страницей заказов
+func TestHandleListOrders_Success(t *testing.T) {
+	// --- Arrange ---
+	mockStore := new(MockOrderGetter)
+	want := database.ListOrdersQuery{
+		Filter: database.OrderFilter{
+			CustomerID:  "test",
+			CreatedFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Bank:        "alpha",
+			NmID:        2389212,
+			Phone:       "+79161234567", // номер приводится к E.164
+		},
+		Sort:   database.SortAmountDesc,
+		Limit:  10,
+		Cursor: "abc",
+	}
+	page := &database.OrderPage{
+		Orders:     []*model.OrderData{{OrderUID: "uid-1"}},
+		NextCursor: "next",
+	}
+	mockStore.On("ListOrders", mock.Anything, want).Return(page, nil).Once()
+	server := New(mockStore, nil, Options{})
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/orders?customer_id=test&created_from=2024-01-01T00:00:00Z&bank=alpha&nm_id=2389212&phone=%2B7%20(916)%20123-45-67&sort=amount_desc&limit=10&cursor=abc", nil)
+	rr := httptest.NewRecorder()
+
+	// --- Act ---
+	server.routes().ServeHTTP(rr, req)
+
+	// --- Assert ---
+	require.Equal(t, http.StatusOK, rr.Code)
+	var body orderListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Len(t, body.Orders, 1)
+	assert.Equal(t, "uid-1", body.Orders[0].OrderUID)
+	assert.Equal(t, "next", body.NextCursor)
+
+	mockStore.AssertExpectations(t)
+}
+
+// TestHandleListOrders_UnescapedPlus - неэкранированный «+» в телефоне не ломает поиск
+func TestHandleListOrders_UnescapedPlus(t *testing.T) {
+	mockStore := new(MockOrderGetter)
+	want := database.ListOrdersQuery{Filter: database.OrderFilter{Phone: "+79161234567"}}
+	mockStore.On("ListOrders", mock.Anything, want).Return(&database.OrderPage{}, nil).Once()
+	server := New(mockStore, nil, Options{})
+
+	rr := httptest.NewRecorder()
+	server.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders?phone=+79161234567", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockStore.AssertExpectations(t)
+}
+
+// TestHandleListOrders_Empty - тест пустого результата: список, а не null
+func TestHandleListOrders_Empty(t *testing.T) {
+	mockStore := new(MockOrderGetter)
+	mockStore.On("ListOrders", mock.Anything, database.ListOrdersQuery{}).Return(&database.OrderPage{}, nil).Once()
+	server := New(mockStore, nil, Options{})
+
+	rr := httptest.NewRecorder()
+	server.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"orders":[]}`, rr.Body.String())
+}
+
+// TestHandleListOrders_BadRequest - тест некорректных параметров запроса
+func TestHandleListOrders_BadRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		err   error // ошибка хранилища; nil, если запрос отклоняется до обращения к нему
+	}{
+		{name: "дата", query: "created_to=yesterday"},
+		{name: "артикул", query: "nm_id=abc"},
+		{name: "отрицательный лимит", query: "limit=-1"},
+		{name: "телефон без кода страны", query: "phone=89161234567"},
+		{name: "курсор", query: "cursor=broken", err: database.ErrInvalidQuery},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := new(MockOrderGetter)
+			if tt.err != nil {
+				mockStore.On("ListOrders", mock.Anything, mock.Anything).Return(nil, tt.err).Once()
+			}
+			server := New(mockStore, nil, Options{})
+
+			rr := httptest.NewRecorder()
+			server.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders?"+tt.query, nil))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			var body errorResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			assert.Equal(t, codeInvalidQuery, body.Code)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleGetOrderByTrack - тест поиска заказа по трек-номеру
+func TestHandleGetOrderByTrack(t *testing.T) {
+	// --- Arrange ---
+	mockStore := new(MockOrderGetter)
+	order := &model.OrderData{OrderUID: "uid-1", TrackNumber: "WBILMTESTTRACK"}
+	mockStore.On("GetOrderByTrackNumber", mock.Anything, "WBILMTESTTRACK").Return(order, nil).Once()
+	mockStore.On("GetOrderByTrackNumber", mock.Anything, "UNKNOWN").Return(nil, database.ErrNotFound).Once()
+	server := New(mockStore, nil, Options{})
+
+	// --- Act ---
+	found := httptest.NewRecorder()
+	server.routes().ServeHTTP(found, httptest.NewRequest(http.MethodGet, "/track/WBILMTESTTRACK", nil))
+	missing := httptest.NewRecorder()
+	server.routes().ServeHTTP(missing, httptest.NewRequest(http.MethodGet, "/track/UNKNOWN", nil))
+
+	// --- Assert ---
+	require.Equal(t, http.StatusOK, found.Code)
+	var got model.OrderData
+	require.NoError(t, json.Unmarshal(found.Body.Bytes(), &got))
+	assert.Equal(t, "uid-1", got.OrderUID)
+
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+	mockStore.AssertExpectations(t)
+}
+
+// TestHandleCustomerOrders - тест списка заказов покупателя с пагинацией
+func TestHandleCustomerOrders(t *testing.T) {
+	// --- Arrange ---
+	mockStore := new(MockOrderGetter)
+	want := database.ListOrdersQuery{Limit: 5, Cursor: "abc"}
+	page := &database.OrderPage{Orders: []*model.OrderData{{OrderUID: "uid-1", CustomerID: "test"}}}
+	mockStore.On("GetCustomerOrders", mock.Anything, "test", want).Return(page, nil).Once()
+	server := New(mockStore, nil, Options{})
+
+	rr := httptest.NewRecorder()
+
+	// --- Act ---
+	server.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/customers/test/orders?limit=5&cursor=abc", nil))
+
+	// --- Assert ---
+	require.Equal(t, http.StatusOK, rr.Code)
+	var body orderListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Len(t, body.Orders, 1)
+	assert.Empty(t, body.NextCursor)
+	mockStore.AssertExpectations(t)
+}
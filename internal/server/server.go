@@ -3,9 +3,17 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	"l1/internal/health"
+	"l1/internal/metrics"
 	"l1/internal/model"
 )
 
@@ -15,43 +23,205 @@ type OrderGetter interface {
 	GetOrderByUID(ctx context.Context, orderUID string) (*model.OrderData, error)
 }
 
+// Options — таймауты HTTP-сервера. Нулевые значения заменяются значениями по умолчанию.
+type Options struct {
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// Сколько времени есть у обработчика на получение данных (запросы к кэшу и БД)
+	RequestTimeout time.Duration
+
+	// Лента новых заказов (SSE): сколько событий может ждать отправки клиенту
+	// (при переполнении новые события пропускаются), сколько ждать записи одного
+	// события, прежде чем отключить медленного клиента, и как часто отправлять
+	// комментарий, чтобы прокси не закрывали простаивающее соединение
+	StreamBuffer       int
+	StreamWriteTimeout time.Duration
+	StreamHeartbeat    time.Duration
+}
+
+// StatusClientClosedRequest — нестандартный код (как в nginx) для запросов,
+// клиент которых отключился, не дождавшись ответа.
+const StatusClientClosedRequest = 499
+
+func (o Options) withDefaults() Options {
+	if o.ReadTimeout <= 0 {
+		o.ReadTimeout = 5 * time.Second
+	}
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = 10 * time.Second
+	}
+	if o.IdleTimeout <= 0 {
+		o.IdleTimeout = 60 * time.Second
+	}
+	if o.RequestTimeout <= 0 {
+		o.RequestTimeout = 3 * time.Second
+	}
+	if o.StreamBuffer <= 0 {
+		o.StreamBuffer = 64
+	}
+	if o.StreamWriteTimeout <= 0 {
+		o.StreamWriteTimeout = 5 * time.Second
+	}
+	if o.StreamHeartbeat <= 0 {
+		o.StreamHeartbeat = 15 * time.Second
+	}
+	return o
+}
+
 type Server struct {
-	store OrderGetter
+	store          OrderGetter
+	lister         OrderLister     // nil, если хранилище не поддерживает поиск
+	finder         OrderFinder     // nil, если хранилище не поддерживает поиск по трек-номеру и покупателю
+	subscriber     OrderSubscriber // nil, если хранилище не публикует события о новых заказах
+	payments       PaymentHistory  // nil, если хранилище не хранит историю оплат
+	health         *health.Registry
+	component      *health.Component
+	httpServer     *http.Server
+	requestTimeout time.Duration
+	streamBuffer   int
+	streamWrite    time.Duration // таймаут записи события ленты
+	streamPing     time.Duration // период комментария-пульса ленты
+	stopping       chan struct{} // закрывается при остановке, чтобы завершить ленты заказов
+	stopOnce       sync.Once
 }
 
-func New(store OrderGetter) *Server {
-	return &Server{store: store}
+// New создает сервер. Состояние компонентов для /readyz берется из h;
+// если h равен nil, сервер сообщает только о себе.
+func New(store OrderGetter, h *health.Registry, opts Options) *Server {
+	if h == nil {
+		h = health.NewRegistry()
+	}
+	opts = opts.withDefaults()
+
+	s := &Server{
+		store:          store,
+		health:         h,
+		component:      h.Component("http"),
+		requestTimeout: opts.RequestTimeout,
+		streamBuffer:   opts.StreamBuffer,
+		streamWrite:    opts.StreamWriteTimeout,
+		streamPing:     opts.StreamHeartbeat,
+		stopping:       make(chan struct{}),
+	}
+	s.lister, _ = store.(OrderLister)
+	s.finder, _ = store.(OrderFinder)
+	s.subscriber, _ = store.(OrderSubscriber)
+	s.payments, _ = store.(PaymentHistory)
+	s.httpServer = &http.Server{
+		Handler:           s.routes(),
+		ReadTimeout:       opts.ReadTimeout,
+		ReadHeaderTimeout: opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+	}
+	return s
 }
 
-func (s *Server) Start(addr string) error {
-	// API
+// routes регистрирует обработчики сервера.
+func (s *Server) routes() http.Handler {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/order/", s.handleGetOrder)
+
+	// API v1
+	s.handleAPI(mux, "GET /api/v1/orders/{order_uid}", s.handleGetOrder)
+	if s.subscriber != nil {
+		// Более конкретный шаблон, поэтому имеет приоритет над /orders/{order_uid}
+		s.handleAPI(mux, "GET /api/v1/orders/stream", s.handleOrderStream)
+	}
+	if s.payments != nil {
+		s.handleAPI(mux, "GET /api/v1/orders/{order_uid}/payments", s.handleGetPayments)
+	}
+	if s.lister != nil {
+		s.handleAPI(mux, "GET /api/v1/orders", s.handleListOrders)
+	}
+	if s.finder != nil {
+		s.handleAPI(mux, "GET /api/v1/tracks/{track_number}", s.handleGetOrderByTrack)
+		s.handleAPI(mux, "GET /api/v1/customers/{customer_id}/orders", s.handleCustomerOrders)
+	}
+	mux.HandleFunc("GET /api/v1/openapi.json", serveOpenAPI)
+	// Остальные адреса под /api/v1/ отвечают ошибкой в формате API, а не страницей статики
+	s.handleAPI(mux, "/api/v1/", handleAPINotFound)
+
+	// Адреса без версии оставлены для совместимости со старыми клиентами
+	s.handleAPI(mux, "GET /order/{order_uid}", s.handleGetOrder)
+	s.handleAPI(mux, "GET /order/{$}", s.handleGetOrder)
+	if s.lister != nil {
+		s.handleAPI(mux, "GET /orders", s.handleListOrders)
+	}
+	if s.finder != nil {
+		s.handleAPI(mux, "GET /track/{track_number}", s.handleGetOrderByTrack)
+		s.handleAPI(mux, "GET /customers/{customer_id}/orders", s.handleCustomerOrders)
+	}
+
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/healthz", s.health.LivenessHandler())
+	mux.Handle("/readyz", s.health.ReadinessHandler())
 
 	// Статика — всё, что лежит в ./web (после сборки фронтенда)
 	fs := http.FileServer(http.Dir("./web"))
-	mux.Handle("/", fs) // теперь / и прочие пути пойдут в папку web
+	mux.Handle("/", metrics.InstrumentHandler("/", fs)) // теперь / и прочие пути пойдут в папку web
+
+	return mux
+}
+
+// handleAPI регистрирует обработчик API: с идентификатором запроса и метриками,
+// в которых маршрут подписан шаблоном пути без метода.
+func (s *Server) handleAPI(mux *http.ServeMux, pattern string, h http.HandlerFunc) {
+	route := pattern[strings.IndexByte(pattern, '/'):]
+	mux.Handle(pattern, withRequestID(metrics.InstrumentHandler(route, h)))
+}
 
+// Start запускает сервер и блокируется до его остановки. После Shutdown возвращает nil.
+func (s *Server) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		s.component.SetError(err)
+		return fmt.Errorf("не удалось открыть порт %s: %w", addr, err)
+	}
 	log.Printf("Веб-сервер запущен на http://%s", addr)
-	if err := http.ListenAndServe(addr, mux); err != nil {
-		log.Fatalf("Ошибка при запуске сервера: %v", err)
-		return err
+	return s.Serve(ln)
+}
+
+// Serve обслуживает соединения из ln до остановки сервера. После Shutdown возвращает nil.
+func (s *Server) Serve(ln net.Listener) error {
+	s.component.SetReady()
+	if err := s.httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.component.SetError(err)
+		return fmt.Errorf("ошибка веб-сервера: %w", err)
 	}
+	return nil
+}
+
+// Shutdown прекращает прием новых соединений и дожидается завершения текущих
+// запросов, но не дольше, чем до отмены ctx.
+func (s *Server) Shutdown(ctx context.Context) error {
+	// Сразу сообщаем о неготовности, чтобы балансировщик перестал присылать запросы
+	s.component.SetError(errors.New("сервер останавливается"))
+	// Ленты заказов не завершаются сами, поэтому закрываем их до ожидания запросов
+	s.stopOnce.Do(func() { close(s.stopping) })
 
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("не удалось дождаться завершения запросов: %w", err)
+	}
+	log.Println("Веб-сервер остановлен")
 	return nil
 }
 
 func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request) {
-	orderUID := r.URL.Path[len("/order/"):]
+	orderUID := r.PathValue("order_uid")
 	if orderUID == "" {
-		http.Error(w, "Не указан UID заказа", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, codeInvalidUID, "Не указан UID заказа")
 		return
 	}
 
-	order, err := s.store.GetOrderByUID(context.Background(), orderUID)
+	// Запрос к хранилищу отменяется, если клиент отключился или истек бюджет времени
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	defer cancel()
+
+	order, err := s.store.GetOrderByUID(ctx, orderUID)
 	if err != nil {
-		log.Printf("Ошибка получения заказа %s: %v", orderUID, err)
-		http.Error(w, "Заказ не найден", http.StatusNotFound)
+		log.Printf("Ошибка получения заказа %s (request_id=%s): %v", orderUID, requestID(r), err)
+		writeStoreError(ctx, w, r, err)
 		return
 	}
 
@@ -4,10 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"l1/internal/database"
+	"l1/internal/health"
 	"l1/internal/model"
 
 	"github.com/stretchr/testify/assert"
@@ -30,6 +35,43 @@ func (m *MockOrderGetter) GetOrderByUID(ctx context.Context, orderUID string) (*
 	return args.Get(0).(*model.OrderData), args.Error(1)
 }
 
+func (m *MockOrderGetter) ListOrders(ctx context.Context, q database.ListOrdersQuery) (*database.OrderPage, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrderPage), args.Error(1)
+}
+
+func (m *MockOrderGetter) GetOrderByTrackNumber(ctx context.Context, track string) (*model.OrderData, error) {
+	args := m.Called(ctx, track)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.OrderData), args.Error(1)
+}
+
+func (m *MockOrderGetter) GetCustomerOrders(ctx context.Context, customerID string, q database.ListOrdersQuery) (*database.OrderPage, error) {
+	args := m.Called(ctx, customerID, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrderPage), args.Error(1)
+}
+
+func (m *MockOrderGetter) GetPaymentAttempts(ctx context.Context, orderUID string) ([]database.PaymentAttempt, error) {
+	args := m.Called(ctx, orderUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.PaymentAttempt), args.Error(1)
+}
+
+func (m *MockOrderGetter) Subscribe(buffer int) *database.Subscription {
+	args := m.Called(buffer)
+	return args.Get(0).(*database.Subscription)
+}
+
 // --- Тесты ---
 
 // TestHandleGetOrder_Success - тест успешного запроса
@@ -41,14 +83,14 @@ func TestHandleGetOrder_Success(t *testing.T) {
 	mockStore.On("GetOrderByUID", mock.Anything, "test-uid-123").Return(testOrder, nil).Once()
 
 	// Создаем сервер с моком
-	server := New(mockStore)
+	server := New(mockStore, nil, Options{})
 
 	// Создаем фейковый HTTP-запрос
 	req := httptest.NewRequest(http.MethodGet, "/order/test-uid-123", nil)
 	rr := httptest.NewRecorder() // Это "перехватчик" ответа
 
 	// --- Act ---
-	server.handleGetOrder(rr, req)
+	server.routes().ServeHTTP(rr, req)
 
 	// --- Assert ---
 	assert.Equal(t, http.StatusOK, rr.Code, "Код ответа должен быть 200 OK")
@@ -66,15 +108,15 @@ func TestHandleGetOrder_Success(t *testing.T) {
 func TestHandleGetOrder_NotFound(t *testing.T) {
 	// --- Arrange ---
 	mockStore := new(MockOrderGetter)
-	mockStore.On("GetOrderByUID", mock.Anything, "not-found-uid").Return(nil, errors.New("not found")).Once()
+	mockStore.On("GetOrderByUID", mock.Anything, "not-found-uid").Return(nil, fmt.Errorf("заказ с UID not-found-uid не найден: %w", database.ErrNotFound)).Once()
 
-	server := New(mockStore)
+	server := New(mockStore, nil, Options{})
 
 	req := httptest.NewRequest(http.MethodGet, "/order/not-found-uid", nil)
 	rr := httptest.NewRecorder()
 
 	// --- Act ---
-	server.handleGetOrder(rr, req)
+	server.routes().ServeHTTP(rr, req)
 
 	// --- Assert ---
 	assert.Equal(t, http.StatusNotFound, rr.Code, "Код ответа должен быть 404 Not Found")
@@ -83,17 +125,75 @@ func TestHandleGetOrder_NotFound(t *testing.T) {
 	mockStore.AssertExpectations(t)
 }
 
+// TestHandleGetOrder_ErrorMapping - тест соответствия ошибок хранилища кодам ответа
+func TestHandleGetOrder_ErrorMapping(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"некорректный UID", fmt.Errorf("проверка: %w", database.ErrInvalidUID), http.StatusBadRequest, codeInvalidUID},
+		{"не найден", database.ErrNotFound, http.StatusNotFound, codeNotFound},
+		{"хранилище недоступно", fmt.Errorf("запрос: %w", database.ErrUnavailable), http.StatusServiceUnavailable, codeUnavailable},
+		{"внутренняя ошибка", errors.New("syntax error"), http.StatusInternalServerError, codeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// --- Arrange ---
+			mockStore := new(MockOrderGetter)
+			mockStore.On("GetOrderByUID", mock.Anything, "uid-1").Return(nil, tt.err).Once()
+			server := New(mockStore, nil, Options{})
+
+			req := httptest.NewRequest(http.MethodGet, "/order/uid-1", nil)
+			req.Header.Set(RequestIDHeader, "req-42")
+			rr := httptest.NewRecorder()
+
+			// --- Act ---
+			server.routes().ServeHTTP(rr, req)
+
+			// --- Assert ---
+			assert.Equal(t, tt.status, rr.Code)
+			assert.Equal(t, "req-42", rr.Header().Get(RequestIDHeader))
+
+			var body errorResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body), "Тело ошибки должно быть валидным JSON")
+			assert.Equal(t, tt.code, body.Code)
+			assert.NotEmpty(t, body.Message)
+			assert.Equal(t, "req-42", body.RequestID)
+			// Внутренние подробности клиенту не отдаются
+			assert.NotContains(t, rr.Body.String(), "syntax error")
+
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+// TestWithRequestID_Generates - тест создания идентификатора запроса, если клиент его не передал
+func TestWithRequestID_Generates(t *testing.T) {
+	var seen string
+	h := withRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = requestID(r)
+	}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/order/x", nil))
+
+	assert.NotEmpty(t, seen)
+	assert.Equal(t, seen, rr.Header().Get(RequestIDHeader))
+}
+
 // TestHandleGetOrder_BadRequest_NoUID - тест ошибки "не указан UID"
 func TestHandleGetOrder_BadRequest_NoUID(t *testing.T) {
 	// --- Arrange ---
 	mockStore := new(MockOrderGetter)
-	server := New(mockStore)
+	server := New(mockStore, nil, Options{})
 
 	req := httptest.NewRequest(http.MethodGet, "/order/", nil) // Пустой UID
 	rr := httptest.NewRecorder()
 
 	// --- Act ---
-	server.handleGetOrder(rr, req)
+	server.routes().ServeHTTP(rr, req)
 
 	// --- Assert ---
 	assert.Equal(t, http.StatusBadRequest, rr.Code, "Код ответа должен быть 400 Bad Request")
@@ -102,3 +202,149 @@ func TestHandleGetOrder_BadRequest_NoUID(t *testing.T) {
 	// Убедимся, что до GetOrderByUID не вызывался
 	mockStore.AssertNotCalled(t, "GetOrderByUID", mock.Anything, mock.Anything)
 }
+
+// TestHandleGetOrder_UsesRequestContext - тест передачи контекста запроса с бюджетом времени
+func TestHandleGetOrder_UsesRequestContext(t *testing.T) {
+	// --- Arrange ---
+	type ctxKey struct{}
+	mockStore := new(MockOrderGetter)
+	mockStore.On("GetOrderByUID", mock.MatchedBy(func(ctx context.Context) bool {
+		_, hasDeadline := ctx.Deadline()
+		return hasDeadline && ctx.Value(ctxKey{}) == "request"
+	}), "test-uid").Return(&model.OrderData{OrderUID: "test-uid"}, nil).Once()
+
+	server := New(mockStore, nil, Options{RequestTimeout: time.Second})
+	req := httptest.NewRequest(http.MethodGet, "/order/test-uid", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ctxKey{}, "request"))
+	rr := httptest.NewRecorder()
+
+	// --- Act ---
+	server.routes().ServeHTTP(rr, req)
+
+	// --- Assert ---
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockStore.AssertExpectations(t)
+}
+
+// TestHandleGetOrder_Timeout - тест ответа 504 при истечении бюджета времени
+func TestHandleGetOrder_Timeout(t *testing.T) {
+	// --- Arrange ---
+	mockStore := new(MockOrderGetter)
+	mockStore.On("GetOrderByUID", mock.Anything, "slow-uid").
+		Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return(nil, fmt.Errorf("ошибка запроса: %w", context.DeadlineExceeded)).Once()
+
+	server := New(mockStore, nil, Options{RequestTimeout: 20 * time.Millisecond})
+	req := httptest.NewRequest(http.MethodGet, "/order/slow-uid", nil)
+	rr := httptest.NewRecorder()
+
+	// --- Act ---
+	server.routes().ServeHTTP(rr, req)
+
+	// --- Assert ---
+	assert.Equal(t, http.StatusGatewayTimeout, rr.Code, "Таймаут не должен выглядеть как 'не найдено'")
+	mockStore.AssertExpectations(t)
+}
+
+// TestHandleGetOrder_ClientCanceled - тест ответа 499, когда клиент отключился
+func TestHandleGetOrder_ClientCanceled(t *testing.T) {
+	// --- Arrange ---
+	mockStore := new(MockOrderGetter)
+	mockStore.On("GetOrderByUID", mock.Anything, "gone-uid").Return(nil, context.Canceled).Once()
+
+	server := New(mockStore, nil, Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/order/gone-uid", nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	// --- Act ---
+	server.routes().ServeHTTP(rr, req)
+
+	// --- Assert ---
+	assert.Equal(t, StatusClientClosedRequest, rr.Code)
+	mockStore.AssertExpectations(t)
+}
+
+// TestServer_ShutdownDrainsRequests - тест завершения текущих запросов при остановке
+func TestServer_ShutdownDrainsRequests(t *testing.T) {
+	// --- Arrange ---
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockStore := new(MockOrderGetter)
+	mockStore.On("GetOrderByUID", mock.Anything, "slow-uid").
+		Run(func(mock.Arguments) {
+			close(started)
+			<-release
+		}).
+		Return(&model.OrderData{OrderUID: "slow-uid"}, nil).Once()
+
+	registry := health.NewRegistry()
+	server := New(mockStore, registry, Options{})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- server.Serve(ln) }()
+
+	respCh := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/order/slow-uid")
+		if err == nil {
+			respCh <- resp
+		}
+		close(respCh)
+	}()
+	<-started
+
+	// --- Act ---
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- server.Shutdown(context.Background()) }()
+
+	// Пока запрос выполняется, сервер уже не готов принимать трафик
+	require.Eventually(t, func() bool {
+		return registry.Readiness(context.Background()).Components["http"].Status == health.StatusDown
+	}, time.Second, 10*time.Millisecond)
+	close(release)
+
+	// --- Assert ---
+	resp, ok := <-respCh
+	require.True(t, ok, "текущий запрос должен завершиться, а не оборваться")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NoError(t, <-shutdownErr)
+	assert.NoError(t, <-serveErr, "после Shutdown Serve возвращает nil")
+}
+
+// TestServer_ShutdownDeadline - тест остановки по истечении срока
+func TestServer_ShutdownDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	mockStore := new(MockOrderGetter)
+	mockStore.On("GetOrderByUID", mock.Anything, "stuck-uid").
+		Run(func(mock.Arguments) {
+			close(started)
+			<-release
+		}).
+		Return(nil, errors.New("stuck")).Maybe()
+
+	server := New(mockStore, nil, Options{})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(ln) }()
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/order/stuck-uid")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, server.Shutdown(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"l1/internal/database"
+	"l1/internal/metrics"
+)
+
+// OrderSubscriber определяет интерфейс подписки на события о сохранении заказов.
+type OrderSubscriber interface {
+	Subscribe(buffer int) *database.Subscription
+}
+
+// transportSSE — значение метки transport в метриках ленты.
+const transportSSE = "sse"
+
+// handleOrderStream отдает ленту новых заказов в формате Server-Sent Events:
+// GET /api/v1/orders/stream. Фильтры те же, что у GET /orders; sort, limit и
+// cursor не учитываются.
+//
+// События:
+//   - order — сохраненный заказ (data — заказ в JSON, id — его UID);
+//   - dropped — клиент не успевал читать и часть событий пропущена
+//     (data — {"dropped": N}, число пропущенных с прошлого уведомления).
+//
+// Клиент, который не принимает данные дольше StreamWriteTimeout, отключается.
+func (s *Server) handleOrderStream(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, codeInvalidQuery, err.Error())
+		return
+	}
+	filter := q.Filter
+
+	rc := http.NewResponseController(w)
+	// Таймаут чтения сервера иначе оборвал бы долгий запрос; запись ограничивается
+	// отдельно для каждого события
+	_ = rc.SetReadDeadline(time.Time{})
+
+	sub := s.subscriber.Subscribe(s.streamBuffer)
+	defer sub.Close()
+	clients := metrics.StreamClients.With(transportSSE)
+	clients.Add(1)
+	defer clients.Add(-1)
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no") // nginx не должен буферизовать ленту
+	w.WriteHeader(http.StatusOK)
+	// Сразу отправляем заголовки, чтобы клиент знал, что подписка оформлена
+	if err := s.writeEvent(w, rc, ": connected\n\n"); err != nil {
+		s.dropClient(r, "write_error", err)
+		return
+	}
+
+	ping := time.NewTicker(s.streamPing)
+	defer ping.Stop()
+
+	var reported uint64
+	for {
+		var msg string
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.stopping:
+			s.dropClient(r, "shutdown", nil)
+			return
+		case ev, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if filter.Match(ev.Order) {
+				data, err := json.Marshal(ev.Order)
+				if err != nil {
+					log.Printf("Ошибка кодирования заказа %s: %v", ev.Order.OrderUID, err)
+					continue
+				}
+				msg = fmt.Sprintf("event: order\nid: %s\ndata: %s\n\n", ev.Order.OrderUID, data)
+			}
+		case <-ping.C:
+			msg = ": ping\n\n"
+		}
+
+		// О пропусках сообщаем перед очередным сообщением, чтобы клиент мог
+		// перезапросить список через GET /orders
+		if dropped := sub.Dropped(); dropped > reported {
+			metrics.StreamDropped.With(transportSSE).Add(float64(dropped - reported))
+			msg = fmt.Sprintf("event: dropped\ndata: {\"dropped\":%d}\n\n", dropped-reported) + msg
+			reported = dropped
+		}
+		if msg == "" {
+			continue
+		}
+		if err := s.writeEvent(w, rc, msg); err != nil {
+			s.dropClient(r, "write_error", err)
+			return
+		}
+	}
+}
+
+// writeEvent отправляет клиенту msg, ограничивая запись таймаутом StreamWriteTimeout.
+func (s *Server) writeEvent(w http.ResponseWriter, rc *http.ResponseController, msg string) error {
+	// Дедлайн записи сервера отсчитывается от начала запроса, поэтому продлеваем его
+	// для каждого события. Если writer не поддерживает дедлайны (например, в тестах),
+	// пишем без них
+	_ = rc.SetWriteDeadline(time.Now().Add(s.streamWrite))
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
+
+// dropClient учитывает отключение клиента ленты сервером.
+func (s *Server) dropClient(r *http.Request, reason string, err error) {
+	metrics.StreamDisconnects.With(transportSSE, reason).Inc()
+	if err != nil {
+		log.Printf("Клиент ленты заказов отключен (request_id=%s): %v", requestID(r), err)
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"l1/internal/database"
+	"l1/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent — событие ленты, прочитанное клиентом.
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readEvent читает из ленты следующее событие, пропуская комментарии.
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var ev sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && ev.name != "":
+			return ev
+		case strings.HasPrefix(line, "event: "):
+			ev.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			ev.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// openStream запускает сервер и подключается к ленте заказов по адресу target.
+func openStream(t *testing.T, server *Server, target string) *bufio.Reader {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(ln) }()
+	t.Cleanup(func() { _ = server.Shutdown(context.Background()) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+ln.Addr().String()+target, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	body := bufio.NewReader(resp.Body)
+	// Первая строка — комментарий о подключении: подписка уже оформлена
+	line, err := body.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, ": connected\n", line)
+	return body
+}
+
+// TestHandleOrderStream_Filter - тест отправки в ленту только подходящих заказов
+func TestHandleOrderStream_Filter(t *testing.T) {
+	// --- Arrange ---
+	events := database.NewBroadcaster()
+	mockStore := new(MockOrderGetter)
+	mockStore.On("Subscribe", 64).Return(events.Subscribe(64)).Once()
+	body := openStream(t, New(mockStore, nil, Options{}), "/api/v1/orders/stream?customer_id=test")
+
+	// --- Act ---
+	events.Publish(database.OrderEvent{Order: &model.OrderData{OrderUID: "uid-other", CustomerID: "other"}})
+	events.Publish(database.OrderEvent{Order: &model.OrderData{OrderUID: "uid-1", CustomerID: "test"}})
+
+	// --- Assert ---
+	ev := readEvent(t, body)
+	assert.Equal(t, "order", ev.name)
+	var order model.OrderData
+	require.NoError(t, json.Unmarshal([]byte(ev.data), &order))
+	assert.Equal(t, "uid-1", order.OrderUID, "заказ другого покупателя не должен попасть в ленту")
+	mockStore.AssertExpectations(t)
+}
+
+// TestHandleOrderStream_ReportsDropped - тест уведомления медленного клиента о пропусках
+func TestHandleOrderStream_ReportsDropped(t *testing.T) {
+	// --- Arrange ---
+	// Буфер на одно событие: два из трех опубликованных до чтения пропускаются
+	events := database.NewBroadcaster()
+	sub := events.Subscribe(1)
+	for _, uid := range []string{"uid-1", "uid-2", "uid-3"} {
+		events.Publish(database.OrderEvent{Order: &model.OrderData{OrderUID: uid}})
+	}
+	mockStore := new(MockOrderGetter)
+	mockStore.On("Subscribe", mock.Anything).Return(sub).Once()
+
+	// --- Act ---
+	body := openStream(t, New(mockStore, nil, Options{}), "/api/v1/orders/stream")
+
+	// --- Assert ---
+	ev := readEvent(t, body)
+	assert.Equal(t, sseEvent{name: "dropped", data: `{"dropped":2}`}, ev)
+	ev = readEvent(t, body)
+	assert.Equal(t, "order", ev.name)
+	assert.Contains(t, ev.data, `"order_uid":"uid-1"`)
+}
+
+// TestServer_ShutdownEndsStreams - тест завершения лент при остановке сервера
+func TestServer_ShutdownEndsStreams(t *testing.T) {
+	// --- Arrange ---
+	events := database.NewBroadcaster()
+	mockStore := new(MockOrderGetter)
+	mockStore.On("Subscribe", mock.Anything).Return(events.Subscribe(1)).Once()
+	server := New(mockStore, nil, Options{})
+	body := openStream(t, server, "/api/v1/orders/stream")
+
+	// --- Act ---
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err := server.Shutdown(ctx)
+
+	// --- Assert ---
+	require.NoError(t, err, "остановка не должна ждать открытых лент")
+	_, err = io.ReadAll(body)
+	assert.NoError(t, err, "лента должна завершиться корректно")
+}
@@ -0,0 +1,105 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"l1/internal/model"
+)
+
+// Коды нарушений согласованности заказа.
+const (
+	CodeMismatch   = "mismatch"     // значение не сходится с рассчитанным по другим полям
+	CodeOutOfRange = "out_of_range" // значение вне допустимого окна
+)
+
+// ConsistencyOptions — допуски проверки согласованности. Суммы указываются
+// в тех же единицах, что и в заказе.
+type ConsistencyOptions struct {
+	// AmountTolerance — допустимое расхождение payment.amount с
+	// goods_total + delivery_cost + custom_fee и goods_total с суммой total_price товаров.
+	AmountTolerance int
+	// PriceTolerance — допустимое расхождение total_price товара с ценой
+	// за вычетом скидки (sale, %); покрывает округление.
+	PriceTolerance int
+	// PaymentWindow — насколько payment_dt может отличаться от date_created
+	// в любую сторону; 0 — не проверять.
+	PaymentWindow time.Duration
+}
+
+// Consistency проверяет, что поля заказа согласованы между собой: суммы оплаты
+// сходятся с товарами, цены — со скидками, трек-номера товаров — с заказом,
+// время оплаты — со временем создания заказа.
+type Consistency struct {
+	opts ConsistencyOptions
+}
+
+// NewConsistency создает проверку согласованности с заданными допусками.
+func NewConsistency(opts ConsistencyOptions) *Consistency {
+	return &Consistency{opts: opts}
+}
+
+// Validate возвращает ValidationErrors со всеми найденными расхождениями или nil.
+func (c *Consistency) Validate(order *model.OrderData) error {
+	var errs ValidationErrors
+	mismatch := func(field string, got, want int, tolerance int) {
+		if abs(got-want) > tolerance {
+			errs = append(errs, FieldError{
+				Field:   field,
+				Code:    CodeMismatch,
+				Message: fmt.Sprintf("значение %d не сходится с расчетным %d", got, want),
+			})
+		}
+	}
+
+	p := order.Payment
+	mismatch("payment.amount", p.Amount, p.GoodsTotal+p.DeliveryCost+p.CustomFee, c.opts.AmountTolerance)
+
+	itemsTotal := 0
+	for _, item := range order.Items {
+		itemsTotal += item.TotalPrice
+	}
+	mismatch("payment.goods_total", p.GoodsTotal, itemsTotal, c.opts.AmountTolerance)
+
+	for i, item := range order.Items {
+		prefix := "items[" + strconv.Itoa(i) + "]"
+		mismatch(prefix+".total_price", item.TotalPrice, discounted(item.Price, item.Sale), c.opts.PriceTolerance)
+		if item.TrackNumber != order.TrackNumber {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".track_number",
+				Code:    CodeMismatch,
+				Message: fmt.Sprintf("трек-номер %q не совпадает с трек-номером заказа %q", item.TrackNumber, order.TrackNumber),
+			})
+		}
+	}
+
+	if c.opts.PaymentWindow > 0 && !order.DateCreated.IsZero() {
+		paid := time.Unix(p.PaymentDt, 0)
+		if d := paid.Sub(order.DateCreated); d > c.opts.PaymentWindow || d < -c.opts.PaymentWindow {
+			errs = append(errs, FieldError{
+				Field:   "payment.payment_dt",
+				Code:    CodeOutOfRange,
+				Message: fmt.Sprintf("время оплаты %s отличается от времени создания заказа больше чем на %s", paid.UTC().Format(time.RFC3339), c.opts.PaymentWindow),
+			})
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// discounted — цена со скидкой sale процентов, округленная до целого.
+func discounted(price, sale int) int {
+	return int(math.Round(float64(price) * float64(100-sale) / 100))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
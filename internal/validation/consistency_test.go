@@ -0,0 +1,58 @@
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"l1/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConsistency_ValidOrder проверяет, что тестовый заказ проходит проверку
+// с допусками по умолчанию (453 со скидкой 30% — 317,1, округляется до 317;
+// оплачен в момент создания)
+func TestConsistency_ValidOrder(t *testing.T) {
+	c := NewConsistency(ConsistencyOptions{PriceTolerance: 1, PaymentWindow: 72 * time.Hour})
+
+	assert.NoError(t, c.Validate(loadTestOrder(t)))
+}
+
+// TestConsistency_ReportsAllMismatches проверяет все инварианты сразу
+func TestConsistency_ReportsAllMismatches(t *testing.T) {
+	// --- Arrange ---
+	order := loadTestOrder(t)
+	order.Payment.Amount = 2000
+	order.Items = append(order.Items, model.Item{TrackNumber: "OTHER", Price: 100, Sale: 0, TotalPrice: 90})
+	order.Payment.PaymentDt = order.DateCreated.Add(2 * time.Hour).Unix()
+
+	// --- Act ---
+	err := NewConsistency(ConsistencyOptions{PaymentWindow: time.Hour}).Validate(order)
+
+	// --- Assert ---
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, []string{
+		"payment.amount",
+		"payment.goods_total",
+		"items[1].total_price",
+		"items[1].track_number",
+		"payment.payment_dt",
+	}, fields(errs))
+	assert.Equal(t, CodeOutOfRange, errs[4].Code)
+	assert.Equal(t, "значение 2000 не сходится с расчетным 1817", errs[0].Message)
+}
+
+// TestConsistency_Tolerances проверяет, что расхождения в пределах допусков не считаются ошибкой
+func TestConsistency_Tolerances(t *testing.T) {
+	order := loadTestOrder(t)
+	order.Payment.Amount += 2
+	order.Items[0].TotalPrice = 316 // округление вниз вместо математического
+	order.Payment.GoodsTotal = 316
+	order.Payment.PaymentDt = 0 // окно оплаты не задано — время не проверяется
+
+	err := NewConsistency(ConsistencyOptions{AmountTolerance: 3, PriceTolerance: 1}).Validate(order)
+
+	assert.NoError(t, err)
+}
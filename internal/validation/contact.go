@@ -0,0 +1,164 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"l1/internal/model"
+)
+
+// Коды нарушений в контактах доставки.
+const (
+	CodeCountry = "country"
+	CodePhone   = "phone"
+	CodeZip     = "zip"
+)
+
+// E.164: код страны и номер вместе — не больше 15 цифр.
+const (
+	minPhoneDigits = 8
+	maxPhoneDigits = 15
+)
+
+// country — телефонный план и формат индекса страны.
+type country struct {
+	code     string         // телефонный код страны
+	trunk    string         // префикс междугороднего набора внутри страны (8 в России)
+	national [2]int         // длина национального номера без кода страны: от и до
+	zip      *regexp.Regexp // формат почтового индекса
+}
+
+// countries — страны, для которых проверяются длина номера и формат индекса.
+// Для остальных телефон проверяется только по общим правилам E.164.
+var countries = map[string]country{
+	"RU": {code: "7", trunk: "8", national: [2]int{10, 10}, zip: regexp.MustCompile(`^\d{6}$`)},
+	"KZ": {code: "7", trunk: "8", national: [2]int{10, 10}, zip: regexp.MustCompile(`^(\d{6}|[A-Z]\d{2}[A-Z]\d[A-Z]\d)$`)},
+	"BY": {code: "375", trunk: "80", national: [2]int{9, 9}, zip: regexp.MustCompile(`^\d{6}$`)},
+	"KG": {code: "996", trunk: "0", national: [2]int{9, 9}, zip: regexp.MustCompile(`^\d{6}$`)},
+	"UZ": {code: "998", national: [2]int{9, 9}, zip: regexp.MustCompile(`^\d{6}$`)},
+	"AM": {code: "374", trunk: "0", national: [2]int{8, 8}, zip: regexp.MustCompile(`^\d{4}$`)},
+	"IL": {code: "972", trunk: "0", national: [2]int{8, 9}, zip: regexp.MustCompile(`^\d{7}$`)},
+	"DE": {code: "49", trunk: "0", national: [2]int{6, 11}, zip: regexp.MustCompile(`^\d{5}$`)},
+	"US": {code: "1", trunk: "1", national: [2]int{10, 10}, zip: regexp.MustCompile(`^\d{5}(-\d{4})?$`)},
+	"GB": {code: "44", trunk: "0", national: [2]int{9, 10}, zip: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`)},
+}
+
+// languageCountries — языки локали, однозначно указывающие на страну.
+var languageCountries = map[string]string{
+	"ru": "RU", "kk": "KZ", "be": "BY", "ky": "KG", "uz": "UZ", "hy": "AM", "he": "IL", "de": "DE",
+}
+
+var countryCode = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// NormalizeCountry приводит код страны к ISO 3166-1 alpha-2 в верхнем регистре
+// (« ru» — «RU»). Возвращает false, если code не похож на такой код.
+func NormalizeCountry(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return code, countryCode.MatchString(code)
+}
+
+// CountryOf определяет страну доставки (ISO 3166-1 alpha-2): поле delivery.country,
+// иначе регион локали (ru-RU, en_US), иначе язык локали, если он указывает на одну
+// страну. Пустая строка — страну определить нельзя.
+func CountryOf(order *model.OrderData) string {
+	if order.Delivery.Country != "" {
+		code, _ := NormalizeCountry(order.Delivery.Country)
+		return code
+	}
+	lang, region, _ := strings.Cut(strings.ReplaceAll(order.Locale, "_", "-"), "-")
+	if region = strings.ToUpper(region); countryCode.MatchString(region) {
+		return region
+	}
+	return languageCountries[strings.ToLower(lang)]
+}
+
+// NormalizePhone приводит номер к формату E.164: «+», код страны и номер без
+// разделителей. Номер без кода страны («8 916 123-45-67») дополняется кодом
+// страны cc; если она неизвестна, такой номер не нормализуется. Для номеров
+// известной страны проверяется длина национального номера.
+func NormalizePhone(phone, cc string) (string, error) {
+	var b strings.Builder
+	international := false
+	for i, r := range strings.TrimSpace(phone) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			international = true
+		case strings.ContainsRune(" -().", r):
+		default:
+			return "", fmt.Errorf("недопустимый символ %q в номере телефона", r)
+		}
+	}
+	digits := b.String()
+	if !international && strings.HasPrefix(digits, "00") {
+		digits, international = digits[2:], true
+	}
+
+	c, known := countries[strings.ToUpper(cc)]
+	if !international {
+		if !known {
+			return "", errors.New("номер указан без кода страны, а страна доставки неизвестна")
+		}
+		switch {
+		case c.trunk != "" && strings.HasPrefix(digits, c.trunk) && c.fits(len(digits)-len(c.trunk)):
+			digits = c.code + digits[len(c.trunk):]
+		case strings.HasPrefix(digits, c.code) && c.fits(len(digits)-len(c.code)):
+		case c.fits(len(digits)):
+			digits = c.code + digits
+		default:
+			return "", fmt.Errorf("номер не похож на номер страны %s", strings.ToUpper(cc))
+		}
+	}
+
+	if len(digits) < minPhoneDigits || len(digits) > maxPhoneDigits {
+		return "", fmt.Errorf("номер с кодом страны должен содержать от %d до %d цифр", minPhoneDigits, maxPhoneDigits)
+	}
+	if known && strings.HasPrefix(digits, c.code) && !c.fits(len(digits)-len(c.code)) {
+		return "", fmt.Errorf("неверная длина номера для страны %s", strings.ToUpper(cc))
+	}
+	return "+" + digits, nil
+}
+
+func (c country) fits(n int) bool {
+	return n >= c.national[0] && n <= c.national[1]
+}
+
+// Contacts проверяет контакты доставки с учетом страны: телефон должен
+// приводиться к E.164, индекс — соответствовать формату страны. Пустые
+// телефон и индекс не проверяются: их обязательность задается правилами.
+type Contacts struct{}
+
+// Validate возвращает ValidationErrors с нарушениями или nil.
+func (Contacts) Validate(order *model.OrderData) error {
+	var errs ValidationErrors
+	d := order.Delivery
+	if _, ok := NormalizeCountry(d.Country); d.Country != "" && !ok {
+		errs = append(errs, FieldError{
+			Field:   "delivery.country",
+			Code:    CodeCountry,
+			Message: fmt.Sprintf("код страны %q должен быть в формате ISO 3166-1 alpha-2", d.Country),
+		})
+	}
+
+	cc := CountryOf(order)
+	if d.Phone != "" {
+		if _, err := NormalizePhone(d.Phone, cc); err != nil {
+			errs = append(errs, FieldError{Field: "delivery.phone", Code: CodePhone, Message: err.Error()})
+		}
+	}
+	if c, ok := countries[cc]; ok && d.Zip != "" && !c.zip.MatchString(strings.ToUpper(d.Zip)) {
+		errs = append(errs, FieldError{
+			Field:   "delivery.zip",
+			Code:    CodeZip,
+			Message: fmt.Sprintf("индекс %q не соответствует формату страны %s", d.Zip, cc),
+		})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
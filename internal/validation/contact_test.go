@@ -0,0 +1,110 @@
+package validation
+
+import (
+	"testing"
+
+	"l1/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalizePhone проверяет приведение номеров к E.164
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		name    string
+		phone   string
+		country string
+		want    string // пустая строка — ожидается ошибка
+	}{
+		{"международный с разделителями", "+7 (916) 123-45-67", "", "+79161234567"},
+		{"префикс 00", "00 49 30 1234567", "", "+49301234567"},
+		{"российский через 8", "8 916 123 45 67", "RU", "+79161234567"},
+		{"российский без +", "79161234567", "RU", "+79161234567"},
+		{"национальный номер", "9161234567", "RU", "+79161234567"},
+		{"белорусский через 80", "80291234567", "BY", "+375291234567"},
+		{"израильский через 0", "050-123-4567", "IL", "+972501234567"},
+		{"иностранный номер не проверяется по стране доставки", "+9720000000", "RU", "+9720000000"},
+		{"без кода страны и без страны", "89161234567", "", ""},
+		{"неверная длина для страны", "+7 916 123-45", "RU", ""},
+		{"слишком короткий", "+123", "", ""},
+		{"буквы", "+7 916 CALL-ME", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePhone(tt.phone, tt.country)
+			if tt.want == "" {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestNormalizeCountry проверяет приведение кода страны и его проверку встроенными правилами
+func TestNormalizeCountry(t *testing.T) {
+	code, ok := NormalizeCountry(" ru ")
+	assert.True(t, ok)
+	assert.Equal(t, "RU", code)
+
+	_, ok = NormalizeCountry("Russia")
+	assert.False(t, ok)
+
+	order := loadTestOrder(t)
+	order.Delivery.Country = "Russia"
+	var errs ValidationErrors
+	require.ErrorAs(t, Default().Validate(order), &errs)
+	assert.Equal(t, []string{"delivery.country"}, fields(errs))
+
+	order.Delivery.Country = "ru"
+	assert.NoError(t, Default().Validate(order))
+}
+
+// TestCountryOf проверяет определение страны доставки
+func TestCountryOf(t *testing.T) {
+	tests := []struct {
+		locale, country, want string
+	}{
+		{"ru", "", "RU"},
+		{"en_US", "", "US"},
+		{"ru-BY", "", "BY"},
+		{"en", "", ""},
+		{"ru", "kz", "KZ"}, // явное поле важнее локали
+	}
+	for _, tt := range tests {
+		order := &model.OrderData{Locale: tt.locale, Delivery: model.Delivery{Country: tt.country}}
+		assert.Equal(t, tt.want, CountryOf(order), "locale=%s country=%s", tt.locale, tt.country)
+	}
+}
+
+// TestContacts проверяет телефон и индекс с учетом страны доставки
+func TestContacts(t *testing.T) {
+	// Тестовый заказ: локаль en, страна не определяется — проверяется только E.164
+	require.NoError(t, Contacts{}.Validate(loadTestOrder(t)))
+
+	// --- Arrange ---
+	order := loadTestOrder(t)
+	order.Delivery.Country = "RU"
+	order.Delivery.Phone = "8 916 123-45-67"
+	order.Delivery.Zip = "2639809" // в России индекс из 6 цифр
+
+	// --- Act ---
+	err := Contacts{}.Validate(order)
+
+	// --- Assert ---
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "delivery.zip", errs[0].Field)
+	assert.Equal(t, CodeZip, errs[0].Code)
+
+	order.Delivery.Zip = "125009"
+	assert.NoError(t, Contacts{}.Validate(order))
+
+	order.Delivery.Country = "Russia"
+	order.Delivery.Phone = "8 916 123-45-67"
+	require.ErrorAs(t, Contacts{}.Validate(order), &errs)
+	assert.Equal(t, []string{"delivery.country", "delivery.phone"}, fields(errs))
+}
@@ -0,0 +1,203 @@
+// Package validation проверяет заказы по набору правил из конфигурации.
+//
+// Правило описывает одно поле заказа по его JSON-пути (delivery.email,
+// items[].price) и ограничения на значение: обязательность, список допустимых
+// значений, регулярное выражение, числовой диапазон, длину строки или число
+// элементов списка. Набор правил читается из YAML или JSON, поэтому требования
+// разных площадок меняются без пересборки сервиса. Проверка сообщает сразу обо
+// всех нарушениях.
+package validation
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"l1/internal/model"
+)
+
+//go:embed default_rules.yaml
+var defaultRules []byte
+
+// Rule — ограничения на одно поле заказа. Пустые ограничения не проверяются.
+type Rule struct {
+	// Field — путь к полю через JSON-имена. Сегмент с суффиксом [] означает
+	// «каждый элемент списка»: items[].price.
+	Field string `yaml:"field"`
+
+	Required   bool     `yaml:"required"`    // значение не пустое (для списков — есть хотя бы один элемент)
+	Enum       []string `yaml:"enum"`        // допустимые значения строки
+	IgnoreCase bool     `yaml:"ignore_case"` // сравнивать Enum без учета регистра
+	Pattern    string   `yaml:"pattern"`     // регулярное выражение для строки
+
+	Min *float64 `yaml:"min"` // границы числа включительно
+	Max *float64 `yaml:"max"`
+
+	MinLength *int `yaml:"min_length"` // длина строки в символах
+	MaxLength *int `yaml:"max_length"`
+
+	MinItems *int `yaml:"min_items"` // число элементов списка
+	MaxItems *int `yaml:"max_items"`
+
+	// Message заменяет стандартный текст ошибки для всех нарушений правила.
+	Message string `yaml:"message"`
+
+	path    []segment
+	kind    valueKind
+	pattern *regexp.Regexp
+}
+
+// segment — шаг пути: индекс поля структуры и признак обхода списка.
+type segment struct {
+	index int
+	each  bool
+}
+
+// valueKind — вид значения, на которое указывает путь правила.
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindNumber
+	kindTime
+	kindList
+	kindOther
+)
+
+// RuleSet — скомпилированный набор правил.
+type RuleSet struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Parse читает набор правил из YAML (JSON — его подмножество) и проверяет,
+// что каждое правило указывает на существующее поле и применимо к нему.
+func Parse(data []byte) (*RuleSet, error) {
+	var rs RuleSet
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&rs); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать правила валидации: %w", err)
+	}
+	if len(rs.Rules) == 0 {
+		return nil, errors.New("набор правил валидации пуст")
+	}
+	for i := range rs.Rules {
+		if err := rs.Rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("правило #%d (%s): %w", i+1, rs.Rules[i].Field, err)
+		}
+	}
+	return &rs, nil
+}
+
+// Load читает набор правил из файла.
+func Load(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать правила валидации: %w", err)
+	}
+	return Parse(data)
+}
+
+// Default возвращает встроенный набор правил, с которым сервис работает,
+// если свой набор не задан.
+var Default = sync.OnceValue(func() *RuleSet {
+	rs, err := Parse(defaultRules)
+	if err != nil {
+		panic(fmt.Sprintf("встроенные правила валидации некорректны: %v", err))
+	}
+	return rs
+})
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// compile разбирает путь правила по типу model.OrderData и проверяет,
+// что ограничения подходят к виду поля.
+func (r *Rule) compile() error {
+	if r.Field == "" {
+		return errors.New("не указано поле")
+	}
+
+	t := reflect.TypeFor[model.OrderData]()
+	for _, name := range strings.Split(r.Field, ".") {
+		name, each := strings.CutSuffix(name, "[]")
+		if t.Kind() != reflect.Struct || t == timeType {
+			return fmt.Errorf("у поля нет вложенного поля %q", name)
+		}
+		index, ok := fieldByJSONName(t, name)
+		if !ok {
+			return fmt.Errorf("неизвестное поле %q", name)
+		}
+		t = t.Field(index).Type
+		if each {
+			if t.Kind() != reflect.Slice {
+				return fmt.Errorf("поле %q не является списком", name)
+			}
+			t = t.Elem()
+		}
+		r.path = append(r.path, segment{index: index, each: each})
+	}
+	r.kind = kindOf(t)
+
+	has := func(set bool, what string, kinds ...valueKind) error {
+		if set && !slices.Contains(kinds, r.kind) {
+			return fmt.Errorf("ограничение %s неприменимо к полю типа %s", what, t)
+		}
+		return nil
+	}
+	return errors.Join(
+		has(len(r.Enum) > 0, "enum", kindString),
+		has(r.Pattern != "", "pattern", kindString),
+		has(r.MinLength != nil || r.MaxLength != nil, "min_length/max_length", kindString),
+		has(r.Min != nil || r.Max != nil, "min/max", kindNumber),
+		has(r.MinItems != nil || r.MaxItems != nil, "min_items/max_items", kindList),
+		r.compilePattern(),
+	)
+}
+
+func (r *Rule) compilePattern() error {
+	if r.Pattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("некорректное регулярное выражение: %w", err)
+	}
+	r.pattern = re
+	return nil
+}
+
+// fieldByJSONName ищет поле структуры по имени из тега json.
+func fieldByJSONName(t reflect.Type, name string) (int, bool) {
+	for i := range t.NumField() {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if tag == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func kindOf(t reflect.Type) valueKind {
+	switch {
+	case t == timeType:
+		return kindTime
+	case t.Kind() == reflect.String:
+		return kindString
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Float64:
+		return kindNumber
+	case t.Kind() == reflect.Slice:
+		return kindList
+	default:
+		return kindOther
+	}
+}
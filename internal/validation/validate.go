@@ -0,0 +1,200 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"l1/internal/model"
+)
+
+// Коды нарушений в FieldError.Code.
+const (
+	CodeRequired  = "required"
+	CodeEnum      = "enum"
+	CodePattern   = "pattern"
+	CodeMin       = "min"
+	CodeMax       = "max"
+	CodeMinLength = "min_length"
+	CodeMaxLength = "max_length"
+	CodeMinItems  = "min_items"
+	CodeMaxItems  = "max_items"
+)
+
+// FieldError — нарушение правила в конкретном поле заказа.
+type FieldError struct {
+	Field   string `json:"field"` // путь с индексами элементов: items[0].price
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors — все нарушения, найденные в заказе, в порядке правил.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate проверяет заказ по всем правилам. Возвращает ValidationErrors
+// со всеми нарушениями или nil, если их нет.
+func (rs *RuleSet) Validate(order *model.OrderData) error {
+	return rs.validate(order, "")
+}
+
+// ValidateDelivery проверяет данные доставки по правилам delivery.*.
+func (rs *RuleSet) ValidateDelivery(d *model.Delivery) error {
+	return rs.validate(&model.OrderData{Delivery: *d}, "delivery.")
+}
+
+// ValidatePayment проверяет оплату по правилам payment.*.
+func (rs *RuleSet) ValidatePayment(p *model.Payment) error {
+	return rs.validate(&model.OrderData{Payment: *p}, "payment.")
+}
+
+// ValidateItem проверяет товар по правилам items[].*; в путях нарушений
+// товар указывается как items[0].
+func (rs *RuleSet) ValidateItem(item *model.Item) error {
+	return rs.validate(&model.OrderData{Items: []model.Item{*item}}, "items[].")
+}
+
+// validate проверяет заказ по правилам, путь которых начинается с prefix.
+func (rs *RuleSet) validate(order *model.OrderData, prefix string) error {
+	var errs ValidationErrors
+	for i := range rs.Rules {
+		r := &rs.Rules[i]
+		if !strings.HasPrefix(r.Field, prefix) {
+			continue
+		}
+		r.walk(reflect.ValueOf(order).Elem(), 0, "", func(field string, v reflect.Value) {
+			if code, msg := r.check(v); code != "" {
+				if r.Message != "" {
+					msg = r.Message
+				}
+				errs = append(errs, FieldError{Field: field, Code: code, Message: msg})
+			}
+		})
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// walk проходит путь правила начиная с шага step и вызывает fn для каждого
+// значения, на которое он указывает, вместе с путем до него.
+func (r *Rule) walk(v reflect.Value, step int, prefix string, fn func(field string, v reflect.Value)) {
+	if step == len(r.path) {
+		fn(prefix, v)
+		return
+	}
+	seg := r.path[step]
+	f := v.Field(seg.index)
+	name := v.Type().Field(seg.index).Tag.Get("json")
+	name, _, _ = strings.Cut(name, ",")
+	if prefix != "" {
+		name = prefix + "." + name
+	}
+	if !seg.each {
+		r.walk(f, step+1, name, fn)
+		return
+	}
+	for i := range f.Len() {
+		r.walk(f.Index(i), step+1, name+"["+strconv.Itoa(i)+"]", fn)
+	}
+}
+
+// check проверяет одно значение и возвращает код и текст первого нарушения.
+// Пустая строка без required не проверяется остальными ограничениями:
+// необязательное поле можно не заполнять.
+func (r *Rule) check(v reflect.Value) (code, msg string) {
+	switch r.kind {
+	case kindString:
+		s := v.String()
+		if s == "" {
+			if r.Required {
+				return CodeRequired, "поле обязательно"
+			}
+			return "", ""
+		}
+		return r.checkString(s)
+	case kindNumber:
+		n := number(v)
+		if r.Required && n == 0 {
+			return CodeRequired, "поле обязательно"
+		}
+		if r.Min != nil && n < *r.Min {
+			return CodeMin, "значение должно быть не меньше " + formatNumber(*r.Min)
+		}
+		if r.Max != nil && n > *r.Max {
+			return CodeMax, "значение должно быть не больше " + formatNumber(*r.Max)
+		}
+	case kindList:
+		n := v.Len()
+		if r.Required && n == 0 {
+			return CodeRequired, "список не может быть пустым"
+		}
+		if r.MinItems != nil && n < *r.MinItems {
+			return CodeMinItems, fmt.Sprintf("в списке должно быть не меньше %d элементов", *r.MinItems)
+		}
+		if r.MaxItems != nil && n > *r.MaxItems {
+			return CodeMaxItems, fmt.Sprintf("в списке должно быть не больше %d элементов", *r.MaxItems)
+		}
+	default:
+		if r.Required && v.IsZero() {
+			return CodeRequired, "поле обязательно"
+		}
+	}
+	return "", ""
+}
+
+func (r *Rule) checkString(s string) (code, msg string) {
+	if len(r.Enum) > 0 && !r.inEnum(s) {
+		return CodeEnum, fmt.Sprintf("значение %q не входит в список допустимых: %s", s, strings.Join(r.Enum, ", "))
+	}
+	if r.pattern != nil && !r.pattern.MatchString(s) {
+		return CodePattern, "значение имеет неверный формат"
+	}
+	n := utf8.RuneCountInString(s)
+	if r.MinLength != nil && n < *r.MinLength {
+		return CodeMinLength, fmt.Sprintf("длина должна быть не меньше %d символов", *r.MinLength)
+	}
+	if r.MaxLength != nil && n > *r.MaxLength {
+		return CodeMaxLength, fmt.Sprintf("длина должна быть не больше %d символов", *r.MaxLength)
+	}
+	return "", ""
+}
+
+func (r *Rule) inEnum(s string) bool {
+	for _, allowed := range r.Enum {
+		if s == allowed || r.IgnoreCase && strings.EqualFold(s, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// number приводит целое или дробное значение к float64.
+func number(v reflect.Value) float64 {
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
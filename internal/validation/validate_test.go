@@ -0,0 +1,135 @@
+package validation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"l1/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadTestOrder читает тестовый заказ из JSON-файла.
+func loadTestOrder(t *testing.T) *model.OrderData {
+	t.Helper()
+	data, err := os.ReadFile("../../internal/test/test_model.json")
+	require.NoError(t, err)
+	var order model.OrderData
+	require.NoError(t, json.Unmarshal(data, &order))
+	return &order
+}
+
+// TestDefault_ValidOrder проверяет, что тестовый заказ проходит встроенные правила
+func TestDefault_ValidOrder(t *testing.T) {
+	assert.NoError(t, Default().Validate(loadTestOrder(t)))
+}
+
+// TestDefault_ReportsAllViolations проверяет, что возвращаются все нарушения с путями и кодами
+func TestDefault_ReportsAllViolations(t *testing.T) {
+	// --- Arrange ---
+	order := loadTestOrder(t)
+	order.TrackNumber = ""
+	order.Delivery.Email = "not-an-email"
+	order.Payment.Currency = "usd" // регистр не важен
+	order.Payment.Amount = -1
+	order.Items = append(order.Items, model.Item{ChrtID: 1, TrackNumber: "T", Rid: "r", Name: "n", Size: "0", NmID: 1, Brand: "b", Price: -5})
+
+	// --- Act ---
+	err := Default().Validate(order)
+
+	// --- Assert ---
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, []string{"track_number", "delivery.email", "payment.amount", "items[1].price"}, fields(errs))
+	assert.Equal(t, CodeRequired, errs[0].Code)
+	assert.Equal(t, CodePattern, errs[1].Code)
+	assert.Equal(t, CodeMin, errs[2].Code)
+	assert.Equal(t, CodeMin, errs[3].Code)
+}
+
+// TestRuleSet_ValidateParts проверяет проверку частей заказа только их правилами
+func TestRuleSet_ValidateParts(t *testing.T) {
+	order := loadTestOrder(t)
+	require.NoError(t, Default().ValidateDelivery(&order.Delivery))
+	require.NoError(t, Default().ValidatePayment(&order.Payment))
+	require.NoError(t, Default().ValidateItem(&order.Items[0]))
+
+	order.Delivery.Email = ""
+	order.Payment.Currency = "XXX"
+	order.Items[0].Price = -1
+
+	assert.EqualError(t, Default().ValidateDelivery(&order.Delivery), "delivery.email: поле обязательно")
+	var errs ValidationErrors
+	require.ErrorAs(t, Default().ValidatePayment(&order.Payment), &errs)
+	assert.Equal(t, []string{"payment.currency"}, fields(errs))
+	require.ErrorAs(t, Default().ValidateItem(&order.Items[0]), &errs)
+	assert.Equal(t, []string{"items[0].price"}, fields(errs))
+}
+
+// TestParse_CustomRules проверяет правила площадки в JSON: свой список валют, длину и число товаров
+func TestParse_CustomRules(t *testing.T) {
+	rules, err := Parse([]byte(`{"rules": [
+		{"field": "payment.currency", "enum": ["KZT"], "message": "валюта площадки — KZT"},
+		{"field": "delivery.zip", "pattern": "^\\d{6}$"},
+		{"field": "items[].name", "max_length": 3},
+		{"field": "items", "max_items": 0},
+		{"field": "payment.amount", "max": 1000}
+	]}`))
+	require.NoError(t, err)
+
+	err = rules.Validate(loadTestOrder(t))
+
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, ValidationErrors{
+		{Field: "payment.currency", Code: CodeEnum, Message: "валюта площадки — KZT"},
+		{Field: "delivery.zip", Code: CodePattern, Message: "значение имеет неверный формат"},
+		{Field: "items[0].name", Code: CodeMaxLength, Message: "длина должна быть не больше 3 символов"},
+		{Field: "items", Code: CodeMaxItems, Message: "в списке должно быть не больше 0 элементов"},
+		{Field: "payment.amount", Code: CodeMax, Message: "значение должно быть не больше 1000"},
+	}, errs)
+}
+
+// TestParse_Errors проверяет отказ при некорректных правилах
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"пустой набор":               `rules: []`,
+		"неизвестный ключ":           `rules: [{field: order_uid, requried: true}]`,
+		"неизвестное поле":           `rules: [{field: delivery.state, required: true}]`,
+		"обход не списка":            `rules: [{field: "delivery[].name", required: true}]`,
+		"вложенное поле у строки":    `rules: [{field: order_uid.value, required: true}]`,
+		"enum у числа":               `rules: [{field: sm_id, enum: ["1"]}]`,
+		"min у строки":               `rules: [{field: order_uid, min: 1}]`,
+		"битое регулярное выражение": `rules: [{field: order_uid, pattern: "("}]`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestLoad читает правила из файла
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  - field: delivery.region\n    required: true\n"), 0o600))
+
+	rules, err := Load(path)
+	require.NoError(t, err)
+
+	order := loadTestOrder(t)
+	order.Delivery.Region = ""
+	assert.EqualError(t, rules.Validate(order), "delivery.region: поле обязательно")
+}
+
+func fields(errs ValidationErrors) []string {
+	out := make([]string, len(errs))
+	for i, e := range errs {
+		out[i] = e.Field
+	}
+	return out
+}
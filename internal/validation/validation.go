@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"errors"
+
+	"l1/internal/model"
+)
+
+// Validator проверяет заказ и возвращает ValidationErrors при нарушениях.
+type Validator interface {
+	Validate(order *model.OrderData) error
+}
+
+// All объединяет проверки: заказ проверяется каждой из них, а нарушения
+// собираются в один ValidationErrors.
+type All []Validator
+
+// Validate выполняет все проверки. Ошибка, не являющаяся ValidationErrors,
+// возвращается сразу.
+func (all All) Validate(order *model.OrderData) error {
+	var errs ValidationErrors
+	for _, v := range all {
+		err := v.Validate(order)
+		if err == nil {
+			continue
+		}
+		var verrs ValidationErrors
+		if !errors.As(err, &verrs) {
+			return err
+		}
+		errs = append(errs, verrs...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"l1/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAll проверяет объединение нарушений нескольких проверок
+func TestAll(t *testing.T) {
+	order := loadTestOrder(t)
+	order.Delivery.Region = ""
+	order.Payment.Amount = 1
+
+	err := All{Default(), NewConsistency(ConsistencyOptions{})}.Validate(order)
+
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, []string{"delivery.region", "payment.amount"}, fields(errs))
+}
+
+type failingValidator struct{ err error }
+
+func (f failingValidator) Validate(*model.OrderData) error { return f.err }
+
+// TestAll_OtherError проверяет, что посторонняя ошибка возвращается как есть
+func TestAll_OtherError(t *testing.T) {
+	boom := errors.New("boom")
+
+	err := All{failingValidator{boom}, Default()}.Validate(loadTestOrder(t))
+
+	assert.ErrorIs(t, err, boom)
+}
@@ -18,8 +18,11 @@ document.addEventListener('DOMContentLoaded', () => {
         tbody.innerHTML = '';
 
         try {
-            const res = await fetch(`/order/${orderUid}`);
-            if (!res.ok) throw new Error(`Ошибка запроса: ${res.status}`);
+            const res = await fetch(`/api/v1/orders/${encodeURIComponent(orderUid)}`);
+            if (!res.ok) {
+                const body = await res.json().catch(() => null);
+                throw new Error(body?.message || `Ошибка запроса: ${res.status}`);
+            }
 
             const data = await res.json();
 
@@ -73,4 +76,57 @@ document.addEventListener('DOMContentLoaded', () => {
             loader.classList.add('hidden');
         }
     });
+
+    // Лента новых заказов: сервер присылает события по мере сохранения заказов
+    const feedList = document.getElementById('feed-list');
+    const feedStatus = document.getElementById('feed-status');
+    const feedEmpty = document.getElementById('feed-empty');
+    const feedDropped = document.getElementById('feed-dropped');
+    const feedLimit = 20;
+    let droppedTotal = 0;
+
+    const feed = new EventSource('/api/v1/orders/stream');
+    feed.addEventListener('open', () => {
+        feedStatus.textContent = 'Онлайн';
+    });
+    // EventSource переподключается сам; пока соединения нет, показываем это
+    feed.addEventListener('error', () => {
+        feedStatus.textContent = 'Переподключение…';
+    });
+
+    feed.addEventListener('order', (e) => {
+        const order = JSON.parse(e.data);
+        const li = document.createElement('li');
+        li.className = 'py-2 flex flex-wrap gap-x-4 cursor-pointer hover:bg-slate-100 dark:hover:bg-slate-700';
+        const fields = [
+            order.order_uid,
+            order.track_number,
+            order.customer_id,
+            `${order.payment.amount} ${order.payment.currency}`,
+            new Date(order.date_created).toLocaleString(),
+        ];
+        fields.forEach(text => {
+            const span = document.createElement('span');
+            span.textContent = text;
+            li.appendChild(span);
+        });
+        // По клику показываем заказ в форме выше
+        li.addEventListener('click', () => {
+            input.value = order.order_uid;
+            form.requestSubmit();
+        });
+
+        feedList.prepend(li);
+        while (feedList.children.length > feedLimit) {
+            feedList.lastElementChild.remove();
+        }
+        feedEmpty.classList.add('hidden');
+    });
+
+    // Клиент не успевал читать ленту, и сервер пропустил часть событий
+    feed.addEventListener('dropped', (e) => {
+        droppedTotal += JSON.parse(e.data).dropped;
+        feedDropped.textContent = `Пропущено заказов: ${droppedTotal}. Полный список — в поиске заказов.`;
+        feedDropped.classList.remove('hidden');
+    });
 });